@@ -0,0 +1,202 @@
+package api
+
+// openAPIDocument describes the API, served at /openapi.json.
+const openAPIDocument = `{
+  "openapi": "3.0.3",
+  "info": {
+    "title": "FFXI data API",
+    "version": "1.0.0",
+    "description": "Read-only access to the transformed recipes, merchants, mob drops and harvest data. Every response carries an ETag and answers If-None-Match with 304."
+  },
+  "paths": {
+    "/items": {
+      "get": {
+        "summary": "Search items by name or alias",
+        "parameters": [
+          {"name": "q", "in": "query", "schema": {"type": "string"}, "description": "Text the name or an alias contains, case and underscore insensitive"},
+          {"$ref": "#/components/parameters/limit"}
+        ],
+        "responses": {
+          "200": {"description": "Matching items", "content": {"application/json": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/ItemSummary"}}}}},
+          "400": {"$ref": "#/components/responses/BadRequest"}
+        }
+      }
+    },
+    "/items/{name}": {
+      "get": {
+        "summary": "Look up an item by any spelling",
+        "parameters": [{"$ref": "#/components/parameters/name"}],
+        "responses": {
+          "200": {"description": "The item with every source", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Item"}}}},
+          "404": {"$ref": "#/components/responses/NotFound"}
+        }
+      }
+    },
+    "/items/{name}/vendors": {
+      "get": {
+        "summary": "Merchants selling an item",
+        "parameters": [{"$ref": "#/components/parameters/name"}],
+        "responses": {
+          "200": {"description": "Vendors", "content": {"application/json": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/VendorRef"}}}}},
+          "404": {"$ref": "#/components/responses/NotFound"}
+        }
+      }
+    },
+    "/items/{name}/mobs": {
+      "get": {
+        "summary": "Mobs dropping an item",
+        "parameters": [{"$ref": "#/components/parameters/name"}],
+        "responses": {
+          "200": {"description": "Mobs", "content": {"application/json": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/MobRef"}}}}},
+          "404": {"$ref": "#/components/responses/NotFound"}
+        }
+      }
+    },
+    "/items/{name}/gathering": {
+      "get": {
+        "summary": "Gathering points yielding an item",
+        "parameters": [{"$ref": "#/components/parameters/name"}],
+        "responses": {
+          "200": {"description": "Gathering points", "content": {"application/json": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/GatheringRef"}}}}},
+          "404": {"$ref": "#/components/responses/NotFound"}
+        }
+      }
+    },
+    "/recipes": {
+      "get": {
+        "summary": "Search recipes",
+        "parameters": [
+          {"name": "q", "in": "query", "schema": {"type": "string"}, "description": "Text the recipe name contains"},
+          {"name": "craft", "in": "query", "schema": {"type": "string"}, "description": "Main craft, e.g. Woodworking"},
+          {"name": "result", "in": "query", "schema": {"type": "string"}, "description": "Result item"},
+          {"name": "ingredient", "in": "query", "schema": {"type": "string"}, "description": "Ingredient or crystal item"},
+          {"name": "min_level", "in": "query", "schema": {"type": "integer", "minimum": 0}},
+          {"name": "max_level", "in": "query", "schema": {"type": "integer", "minimum": 0}},
+          {"$ref": "#/components/parameters/limit"}
+        ],
+        "responses": {
+          "200": {"description": "Matching recipes", "content": {"application/json": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/Recipe"}}}}},
+          "400": {"$ref": "#/components/responses/BadRequest"}
+        }
+      }
+    },
+    "/plan": {
+      "get": {
+        "summary": "Cheapest way to get an item, with the plan tree of every ingredient",
+        "parameters": [
+          {"name": "item", "in": "query", "required": true, "schema": {"type": "string"}},
+          {"name": "count", "in": "query", "schema": {"type": "integer", "minimum": 0, "default": 1}},
+          {"name": "level", "in": "query", "schema": {"type": "integer", "minimum": 0}, "description": "Character level, 0 for no limit"},
+          {"name": "skills", "in": "query", "schema": {"type": "string"}, "description": "Craft skills like Woodworking:30,Smithing:12, empty for no limit"},
+          {"name": "mode", "in": "query", "schema": {"type": "string", "enum": ["gil", "time", "weighted"], "default": "gil"}, "description": "Cost to minimize, gil plans leave out farming"}
+        ],
+        "responses": {
+          "200": {"description": "The plan", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Step"}}}},
+          "400": {"$ref": "#/components/responses/BadRequest"}
+        }
+      }
+    },
+    "/profit": {
+      "get": {
+        "summary": "Crafting profit of every recipe",
+        "parameters": [
+          {"name": "craft", "in": "query", "schema": {"type": "string"}},
+          {"name": "merchant_price", "in": "query", "schema": {"type": "string", "enum": ["min", "max", "mid"]}},
+          {"name": "sort", "in": "query", "schema": {"type": "string", "enum": ["Profit", "Level", "IngredientCost", "ExpectedValue", "Margin", "Craft", "Recipe", "Result"]}}
+        ],
+        "responses": {
+          "200": {"description": "Profit rows", "content": {"application/json": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/ProfitRow"}}}}},
+          "400": {"$ref": "#/components/responses/BadRequest"}
+        }
+      }
+    }
+  },
+  "components": {
+    "parameters": {
+      "name": {"name": "name", "in": "path", "required": true, "schema": {"type": "string"}, "description": "Item name in any spelling, URL escaped"},
+      "limit": {"name": "limit", "in": "query", "schema": {"type": "integer", "minimum": 0, "default": 50}}
+    },
+    "responses": {
+      "BadRequest": {"description": "Invalid parameter", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}},
+      "NotFound": {"description": "Unknown item", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}}
+    },
+    "schemas": {
+      "Error": {"type": "object", "properties": {"Error": {"type": "string"}}},
+      "ItemSummary": {"type": "object", "properties": {"Name": {"type": "string"}, "Aliases": {"type": "array", "items": {"type": "string"}}}},
+      "Item": {
+        "type": "object",
+        "properties": {
+          "Name": {"type": "string"},
+          "Aliases": {"type": "array", "items": {"type": "string"}},
+          "ItemDBID": {"type": "integer"},
+          "MadeBy": {"type": "array", "items": {"$ref": "#/components/schemas/RecipeRef"}},
+          "UsedBy": {"type": "array", "items": {"$ref": "#/components/schemas/RecipeRef"}},
+          "Vendors": {"type": "array", "items": {"$ref": "#/components/schemas/VendorRef"}},
+          "Mobs": {"type": "array", "items": {"$ref": "#/components/schemas/MobRef"}},
+          "GatheringPoints": {"type": "array", "items": {"$ref": "#/components/schemas/GatheringRef"}},
+          "Market": {"type": "object", "properties": {"Single": {"type": "number", "nullable": true}, "Stack": {"type": "number", "nullable": true}, "Observations": {"type": "integer"}, "Date": {"type": "string"}}}
+        }
+      },
+      "RecipeRef": {"type": "object", "properties": {"Recipe": {"type": "string"}, "Craft": {"type": "string"}, "Level": {"type": "integer"}, "Count": {"type": "integer"}, "HighQualityLevel": {"type": "integer"}}},
+      "VendorRef": {"type": "object", "properties": {"Merchant": {"type": "string"}, "Zone": {"type": "string"}, "MinPrice": {"type": "integer"}, "MaxPrice": {"type": "integer"}, "RankRequirement": {"type": "string"}}},
+      "MobRef": {"type": "object", "properties": {"Mob": {"type": "string"}, "Zone": {"type": "string"}, "LevelRange": {"type": "object", "nullable": true, "properties": {"Min": {"type": "integer"}, "Max": {"type": "integer"}}}, "Percent": {"type": "number", "nullable": true}, "Source": {"type": "string"}, "AmountDefeated": {"type": "integer"}}},
+      "GatheringRef": {"type": "object", "properties": {"Zone": {"type": "string"}, "PointType": {"type": "string"}, "RequiredTool": {"type": "string"}, "Percent": {"type": "number", "nullable": true}, "Tier": {"type": "string"}}},
+      "Recipe": {
+        "type": "object",
+        "properties": {
+          "SchemaVersion": {"type": "integer"},
+          "Name": {"type": "string"},
+          "Result": {"type": "string"},
+          "Crystal": {"type": "string"},
+          "MainCraft": {"type": "string"},
+          "SkillLevels": {"type": "object", "additionalProperties": {"type": "integer"}},
+          "RequiredItems": {"type": "array", "items": {"type": "object", "properties": {"Name": {"type": "string"}, "Count": {"type": "integer"}}}},
+          "AllPossibleResults": {"type": "array", "items": {"type": "object", "properties": {"Name": {"type": "string"}, "Count": {"type": "integer"}, "HighQualityLevel": {"type": "integer"}}}},
+          "RequiredTools": {"type": "string"},
+          "Provenance": {"$ref": "#/components/schemas/Provenance"}
+        }
+      },
+      "Provenance": {
+        "type": "object",
+        "nullable": true,
+        "properties": {
+          "File": {"type": "string"},
+          "Row": {"type": "integer"},
+          "SourceURL": {"type": "string"},
+          "NPCURL": {"type": "string"},
+          "Links": {"type": "array", "items": {"type": "string"}},
+          "TransformedAt": {"type": "string", "format": "date-time"}
+        }
+      },
+      "Step": {
+        "type": "object",
+        "properties": {
+          "Item": {"type": "string"},
+          "Count": {"type": "integer"},
+          "Method": {"type": "string", "enum": ["buy", "auction", "craft", "farm", "gather", "unavailable"]},
+          "Source": {"type": "string"},
+          "Gil": {"type": "number"},
+          "Seconds": {"type": "number"},
+          "Explanation": {"type": "string"},
+          "Inputs": {"type": "array", "items": {"$ref": "#/components/schemas/Step"}},
+          "Alternatives": {"type": "array", "items": {"type": "object", "properties": {"Method": {"type": "string"}, "Source": {"type": "string"}, "Gil": {"type": "number"}, "Seconds": {"type": "number"}}}}
+        }
+      },
+      "ProfitRow": {
+        "type": "object",
+        "properties": {
+          "Craft": {"type": "string"},
+          "Level": {"type": "integer"},
+          "LevelBand": {"type": "string"},
+          "Recipe": {"type": "string"},
+          "Result": {"type": "string"},
+          "IngredientCost": {"type": "number"},
+          "ExpectedValue": {"type": "number"},
+          "Profit": {"type": "number"},
+          "Margin": {"type": "number"},
+          "MissingPrices": {"type": "array", "items": {"type": "string"}}
+        }
+      }
+    }
+  }
+}`
@@ -0,0 +1,344 @@
+// Package api serves the transformed datasets as a read-only HTTP JSON API.
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"ffxi/dataset"
+	"ffxi/item"
+	"ffxi/planner"
+	"ffxi/profit"
+	"ffxi/recipe"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Server answers API requests from an in-memory dataset. The dataset isn't
+// changed after New, so responses are safe to cache by ETag.
+type Server struct {
+	recipes []recipe.CraftingRecipe
+	catalog *item.Catalog
+	prices  profit.Prices
+	mux     *http.ServeMux
+}
+
+// New returns a server over d. The catalog may carry market prices, prices
+// values recipe results for /profit and may be nil.
+func New(d *dataset.Dataset, catalog *item.Catalog, prices profit.Prices) *Server {
+	if prices == nil {
+		prices = profit.PriceTable{}
+	}
+	s := &Server{recipes: d.Recipes, catalog: catalog, prices: prices, mux: http.NewServeMux()}
+
+	s.mux.HandleFunc("/openapi.json", s.handleOpenAPI)
+	s.mux.HandleFunc("/items", s.handleItems)
+	s.mux.HandleFunc("/items/", s.handleItem)
+	s.mux.HandleFunc("/recipes", s.handleRecipes)
+	s.mux.HandleFunc("/plan", s.handlePlan)
+	s.mux.HandleFunc("/profit", s.handleProfit)
+	return s
+}
+
+// ServeHTTP only allows GET and HEAD, the API is read-only.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		writeError(w, http.StatusMethodNotAllowed, "the API is read-only")
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// apiError is the body of every error response.
+type apiError struct {
+	Error string `json:"Error"`
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	body, _ := json.Marshal(apiError{Error: message})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+// writeJSON writes v with a strong ETag of its content, answering 304 when the
+// client already has it.
+func writeJSON(w http.ResponseWriter, r *http.Request, v interface{}) {
+	body, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "no-cache")
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	if r.Method == http.MethodHead {
+		return
+	}
+	w.Write(body)
+}
+
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	var document interface{}
+	if err := json.Unmarshal([]byte(openAPIDocument), &document); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, r, document)
+}
+
+// ItemSummary is an item in search results.
+type ItemSummary struct {
+	Name    string   `json:"Name"`
+	Aliases []string `json:"Aliases,omitempty"`
+}
+
+// handleItems lists items whose name or alias contains q.
+func (s *Server) handleItems(w http.ResponseWriter, r *http.Request) {
+	query := item.Key(r.URL.Query().Get("q"))
+	limit, ok := intParam(w, r, "limit", 50)
+	if !ok {
+		return
+	}
+
+	results := []ItemSummary{}
+	for _, entry := range s.catalog.Entries() {
+		if len(results) == limit {
+			break
+		}
+		if matchesEntry(entry, query) {
+			results = append(results, ItemSummary{Name: entry.Name, Aliases: entry.Aliases})
+		}
+	}
+	writeJSON(w, r, results)
+}
+
+func matchesEntry(entry *item.Entry, query string) bool {
+	if strings.Contains(item.Key(entry.Name), query) {
+		return true
+	}
+	for _, alias := range entry.Aliases {
+		if strings.Contains(item.Key(alias), query) {
+			return true
+		}
+	}
+	return false
+}
+
+// handleItem serves /items/{name} and its vendors, mobs and gathering
+// sub-resources.
+func (s *Server) handleItem(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.EscapedPath(), "/items/")
+	name, resource := path, ""
+	if i := strings.LastIndex(path, "/"); i >= 0 {
+		name, resource = path[:i], path[i+1:]
+	}
+	name, err := url.PathUnescape(name)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	entry, ok := s.catalog.Lookup(name)
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("no item named %q", name))
+		return
+	}
+
+	switch resource {
+	case "":
+		writeJSON(w, r, entry)
+	case "vendors":
+		writeJSON(w, r, nonNil(entry.Vendors))
+	case "mobs":
+		writeJSON(w, r, nonNil(entry.Mobs))
+	case "gathering":
+		writeJSON(w, r, nonNil(entry.GatheringPoints))
+	default:
+		writeError(w, http.StatusNotFound, fmt.Sprintf("unknown item resource %q", resource))
+	}
+}
+
+// nonNil turns empty reference lists into [] instead of null.
+func nonNil(refs interface{}) interface{} {
+	switch v := refs.(type) {
+	case []item.VendorRef:
+		if v == nil {
+			return []item.VendorRef{}
+		}
+	case []item.MobRef:
+		if v == nil {
+			return []item.MobRef{}
+		}
+	case []item.GatheringRef:
+		if v == nil {
+			return []item.GatheringRef{}
+		}
+	}
+	return refs
+}
+
+// handleRecipes searches recipes by name text, craft, result, ingredient and
+// main craft level.
+func (s *Server) handleRecipes(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	minLevel, ok := intParam(w, r, "min_level", 0)
+	if !ok {
+		return
+	}
+	maxLevel, ok := intParam(w, r, "max_level", 0)
+	if !ok {
+		return
+	}
+	limit, ok := intParam(w, r, "limit", 50)
+	if !ok {
+		return
+	}
+	text := strings.ToLower(query.Get("q"))
+	craft := query.Get("craft")
+	result := query.Get("result")
+	ingredient := query.Get("ingredient")
+
+	results := []recipe.CraftingRecipe{}
+	for _, rec := range s.recipes {
+		if len(results) == limit {
+			break
+		}
+		level := rec.SkillLevels[rec.MainCraft]
+		switch {
+		case text != "" && !strings.Contains(strings.ToLower(rec.Name), text):
+		case craft != "" && !strings.EqualFold(rec.MainCraft, craft):
+		case result != "" && item.Key(rec.Result) != item.Key(result):
+		case ingredient != "" && !usesIngredient(rec, ingredient):
+		case minLevel > 0 && level < minLevel:
+		case maxLevel > 0 && level > maxLevel:
+		default:
+			results = append(results, rec)
+		}
+	}
+	writeJSON(w, r, results)
+}
+
+func usesIngredient(r recipe.CraftingRecipe, name string) bool {
+	for _, ingredient := range r.RequiredItems {
+		if item.Key(ingredient.Name) == item.Key(name) {
+			return true
+		}
+	}
+	return r.Crystal != "" && item.Key(item.CrystalName(r.Crystal)) == item.Key(name)
+}
+
+// handlePlan returns the cheapest plan tree, the bill of materials, for
+// count of an item.
+func (s *Server) handlePlan(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	name := query.Get("item")
+	if name == "" {
+		writeError(w, http.StatusBadRequest, "missing item parameter")
+		return
+	}
+	count, ok := intParam(w, r, "count", 1)
+	if !ok {
+		return
+	}
+	level, ok := intParam(w, r, "level", 0)
+	if !ok {
+		return
+	}
+	skills, err := parseSkills(query.Get("skills"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	mode := query.Get("mode")
+	switch mode {
+	case "", planner.ModeGil, planner.ModeTime, planner.ModeWeighted:
+	default:
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("unknown mode %q", mode))
+		return
+	}
+
+	p := planner.New(s.catalog, s.recipes, planner.Character{Level: level, Skills: skills}, planner.Options{Mode: mode})
+	writeJSON(w, r, p.Plan(name, count))
+}
+
+// parseSkills parses "Woodworking:30,Smithing:12". Empty means no skill limit.
+func parseSkills(list string) (map[string]int, error) {
+	if list == "" {
+		return nil, nil
+	}
+	skills := make(map[string]int)
+	for _, pair := range strings.Split(list, ",") {
+		parts := strings.SplitN(pair, ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("expected craft:level, got %q", pair)
+		}
+		level, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, fmt.Errorf("skill level of %s: %v", parts[0], err)
+		}
+		skills[strings.TrimSpace(parts[0])] = level
+	}
+	return skills, nil
+}
+
+// handleProfit returns the profit of every recipe, optionally of one craft.
+func (s *Server) handleProfit(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	merchantPrice := query.Get("merchant_price")
+	switch merchantPrice {
+	case "", profit.PriceMin, profit.PriceMax, profit.PriceMid:
+	default:
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("unknown merchant_price %q", merchantPrice))
+		return
+	}
+
+	rows := []profit.Row{}
+	for _, row := range profit.Calculate(s.recipes, s.catalog, s.prices, profit.Options{MerchantPrice: merchantPrice}) {
+		if craft := query.Get("craft"); craft == "" || strings.EqualFold(row.Craft, craft) {
+			rows = append(rows, row)
+		}
+	}
+	if column := query.Get("sort"); column != "" {
+		profit.SortRows(rows, column)
+	}
+	writeJSON(w, r, rows)
+}
+
+// intParam reads an optional integer query parameter, answering 400 when it
+// isn't a number.
+func intParam(w http.ResponseWriter, r *http.Request, name string, fallback int) (int, bool) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return fallback, true
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("%s must be a non-negative integer", name))
+		return 0, false
+	}
+	return n, true
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"encoding/json"
+	"ffxi/dataset"
+	"ffxi/merchants"
+	"ffxi/mobdrops"
+	"ffxi/profit"
+	"ffxi/recipe"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func testServer() *Server {
+	percent := 50.0
+	d := &dataset.Dataset{
+		Recipes: []recipe.CraftingRecipe{{
+			Name:          "Woodworking-7-Ash Lumber-From-1-Ash Log",
+			Result:        "Ash Lumber",
+			Crystal:       "Wind",
+			MainCraft:     "Woodworking",
+			SkillLevels:   map[string]int{"Woodworking": 7},
+			RequiredItems: []recipe.Item{{Name: "Ash Log", Count: 1}},
+		}},
+		Merchants: []merchants.MerchantInfo{
+			{Name: "Dahjal", Zone: "Port_Bastok", Items: []merchants.ItemInfo{
+				{Name: "Ash Log", MinPrice: 90, MaxPrice: 110},
+				{Name: "Wind Crystal", MinPrice: 20, MaxPrice: 20},
+			}},
+		},
+		Mobs: []mobdrops.MobInfo{
+			{Name: "Treant Sapling", ZoneName: "Jugner_Forest", ItemDrops: []mobdrops.ItemDrop{{Name: "ash log", Percent: &percent}}},
+		},
+	}
+	return New(d, d.Catalog(), profit.PriceTable{"ash lumber": 300})
+}
+
+func TestServer(t *testing.T) {
+	server := testServer()
+
+	testCases := []struct {
+		path     string
+		status   int
+		contains string
+	}{
+		{"/items?q=ash", http.StatusOK, `"Name": "Ash Lumber"`},
+		{"/items/ash_log", http.StatusOK, `"Name": "Ash Log"`},
+		{"/items/Ash%20Log/vendors", http.StatusOK, `"Merchant": "Dahjal"`},
+		{"/items/Ash%20Log/mobs", http.StatusOK, `"Mob": "Treant Sapling"`},
+		{"/items/Ash%20Log/gathering", http.StatusOK, `[]`},
+		{"/items/Rare%20Sap", http.StatusNotFound, `"Error"`},
+		{"/recipes?craft=woodworking&ingredient=wind%20crystal", http.StatusOK, `"Result": "Ash Lumber"`},
+		{"/recipes?min_level=20", http.StatusOK, `[]`},
+		{"/recipes?min_level=x", http.StatusBadRequest, `min_level`},
+		{"/plan?item=Ash%20Lumber&count=2", http.StatusOK, `"Method": "craft"`},
+		{"/plan", http.StatusBadRequest, `missing item`},
+		{"/profit?craft=Woodworking", http.StatusOK, `"Profit": 170`},
+		{"/openapi.json", http.StatusOK, `"openapi": "3.0.3"`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tc.path, nil))
+			if recorder.Code != tc.status {
+				t.Errorf("Expected status %d, but got %d: %s", tc.status, recorder.Code, recorder.Body)
+			}
+			if !strings.Contains(recorder.Body.String(), tc.contains) {
+				t.Errorf("Expected %s in %s", tc.contains, recorder.Body)
+			}
+			if tc.status == http.StatusOK && !json.Valid(recorder.Body.Bytes()) {
+				t.Errorf("Expected valid JSON, but got %s", recorder.Body)
+			}
+		})
+	}
+}
+
+func TestServerETag(t *testing.T) {
+	server := testServer()
+
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/items/Ash%20Log", nil))
+	etag := recorder.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("Expected an ETag")
+	}
+
+	request := httptest.NewRequest(http.MethodGet, "/items/Ash%20Log", nil)
+	request.Header.Set("If-None-Match", etag)
+	recorder = httptest.NewRecorder()
+	server.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusNotModified || recorder.Body.Len() != 0 {
+		t.Errorf("Expected an empty 304, but got %d: %s", recorder.Code, recorder.Body)
+	}
+
+	recorder = httptest.NewRecorder()
+	server.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/items", nil))
+	if recorder.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for POST, but got %d", recorder.Code)
+	}
+}
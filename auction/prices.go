@@ -0,0 +1,232 @@
+// Package auction turns auction house observations into market prices for
+// the catalog and the profit calculator.
+package auction
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"ffxi/item"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dateLayout is the date format of the price files.
+const dateLayout = "2006-01-02"
+
+// Observation is one auction house sale noted by a player.
+type Observation struct {
+	Item  string    `json:"Item"`
+	Stack bool      `json:"Stack"`
+	Price float64   `json:"Price"`
+	Date  time.Time `json:"Date"`
+}
+
+// observationJSON is how observations are written in JSON price files, with
+// "stack" or "single" for Stack and a 2006-01-02 date.
+type observationJSON struct {
+	Item  string  `json:"Item"`
+	Stack string  `json:"Stack"`
+	Price float64 `json:"Price"`
+	Date  string  `json:"Date"`
+}
+
+// Load reads a user maintained price file. CSV files have item, stack/single,
+// price and date columns with an optional header, JSON files are a list of
+// {"Item", "Stack", "Price", "Date"} objects.
+func Load(filename string) ([]Observation, error) {
+	fileContent, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows [][4]string
+	if strings.HasSuffix(strings.ToLower(filename), ".json") {
+		var observations []observationJSON
+		if err := json.Unmarshal(fileContent, &observations); err != nil {
+			return nil, err
+		}
+		for _, o := range observations {
+			rows = append(rows, [4]string{o.Item, o.Stack, strconv.FormatFloat(o.Price, 'f', -1, 64), o.Date})
+		}
+	} else {
+		reader := csv.NewReader(strings.NewReader(string(fileContent)))
+		reader.FieldsPerRecord = 4
+		records, err := reader.ReadAll()
+		if err != nil {
+			return nil, err
+		}
+		for i, record := range records {
+			if i == 0 && strings.EqualFold(strings.TrimSpace(record[0]), "item") {
+				continue
+			}
+			rows = append(rows, [4]string{record[0], record[1], record[2], record[3]})
+		}
+	}
+
+	var observations []Observation
+	for i, row := range rows {
+		observation, err := parseObservation(row)
+		if err != nil {
+			return nil, fmt.Errorf("%s entry %d: %v", filename, i+1, err)
+		}
+		observations = append(observations, observation)
+	}
+
+	return observations, nil
+}
+
+func parseObservation(row [4]string) (Observation, error) {
+	var stack bool
+	switch strings.ToLower(strings.TrimSpace(row[1])) {
+	case "stack":
+		stack = true
+	case "single", "":
+		stack = false
+	default:
+		return Observation{}, fmt.Errorf("expected stack or single, got %q", row[1])
+	}
+
+	price, err := strconv.ParseFloat(strings.TrimSpace(row[2]), 64)
+	if err != nil {
+		return Observation{}, err
+	}
+	date, err := time.Parse(dateLayout, strings.TrimSpace(row[3]))
+	if err != nil {
+		return Observation{}, err
+	}
+
+	return Observation{Item: strings.TrimSpace(row[0]), Stack: stack, Price: price, Date: date}, nil
+}
+
+// Rules decide which observations make up a price. Zero values use the defaults.
+type Rules struct {
+	// Now is the date prices are judged at, defaulting to today.
+	Now time.Time
+	// MaxAge drops observations older than this before Now.
+	MaxAge time.Duration
+	// Window is how far back from the newest observation to take the median over.
+	Window time.Duration
+}
+
+// Defaults applied to zero-valued Rules fields.
+const (
+	DefaultMaxAge = 30 * 24 * time.Hour
+	DefaultWindow = 7 * 24 * time.Hour
+)
+
+// Quote is the current price of an item as a single and as a stack. A nil
+// price means there is no fresh observation for it.
+type Quote struct {
+	Item         string    `json:"Item"`
+	Single       *float64  `json:"Single"`
+	Stack        *float64  `json:"Stack"`
+	Observations int       `json:"Observations"`
+	Date         time.Time `json:"Date"`
+}
+
+// Table holds a quote per item keyed by item.Key. It implements the Prices
+// interface of the profit package.
+type Table struct {
+	quotes map[string]Quote
+}
+
+// NewTable applies the staleness rules and takes the median price over the
+// window for each item.
+func NewTable(observations []Observation, rules Rules) *Table {
+	rules = withDefaults(rules)
+
+	byItem := make(map[string][]Observation)
+	for _, o := range observations {
+		if rules.Now.Sub(o.Date) > rules.MaxAge {
+			continue
+		}
+		byItem[item.Key(o.Item)] = append(byItem[item.Key(o.Item)], o)
+	}
+
+	table := &Table{quotes: make(map[string]Quote)}
+	for key, itemObservations := range byItem {
+		quote := Quote{Item: itemObservations[0].Item}
+		quote.Single, quote.Date = median(itemObservations, false, rules.Window, quote.Date)
+		var stackDate time.Time
+		quote.Stack, stackDate = median(itemObservations, true, rules.Window, quote.Date)
+		if stackDate.After(quote.Date) {
+			quote.Date = stackDate
+		}
+		quote.Observations = len(itemObservations)
+		table.quotes[key] = quote
+	}
+
+	return table
+}
+
+// median returns the median price of the single or stack observations made
+// within window of the newest one, and the date of the newest one.
+func median(observations []Observation, stack bool, window time.Duration, date time.Time) (*float64, time.Time) {
+	var newest time.Time
+	for _, o := range observations {
+		if o.Stack == stack && o.Date.After(newest) {
+			newest = o.Date
+		}
+	}
+	if newest.IsZero() {
+		return nil, date
+	}
+
+	var prices []float64
+	for _, o := range observations {
+		if o.Stack == stack && newest.Sub(o.Date) <= window {
+			prices = append(prices, o.Price)
+		}
+	}
+	sort.Float64s(prices)
+
+	middle := prices[len(prices)/2]
+	if len(prices)%2 == 0 {
+		middle = (prices[len(prices)/2-1] + middle) / 2
+	}
+	return &middle, newest
+}
+
+// Quote returns the quote for an item by any spelling.
+func (t *Table) Quote(name string) (Quote, bool) {
+	quote, ok := t.quotes[item.Key(name)]
+	return quote, ok
+}
+
+// Price returns the fresh single price of an item.
+func (t *Table) Price(name string) (float64, bool) {
+	quote, ok := t.Quote(name)
+	if !ok || quote.Single == nil {
+		return 0, false
+	}
+	return *quote.Single, true
+}
+
+// Attach records every quote on the catalog entries of the items.
+func (t *Table) Attach(catalog *item.Catalog) {
+	for _, quote := range t.quotes {
+		catalog.SetMarketPrice(quote.Item, item.MarketPrice{
+			Single:       quote.Single,
+			Stack:        quote.Stack,
+			Observations: quote.Observations,
+			Date:         quote.Date.Format(dateLayout),
+		})
+	}
+}
+
+func withDefaults(rules Rules) Rules {
+	if rules.Now.IsZero() {
+		rules.Now = time.Now()
+	}
+	if rules.MaxAge == 0 {
+		rules.MaxAge = DefaultMaxAge
+	}
+	if rules.Window == 0 {
+		rules.Window = DefaultWindow
+	}
+	return rules
+}
@@ -0,0 +1,59 @@
+package auction
+
+import (
+	"ffxi/item"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadAndTable(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "prices.csv")
+	err := os.WriteFile(filename, []byte(`item,stack/single,price,date
+Ash Log,single,100,2026-10-01
+ash log,single,300,2026-10-10
+Ash_Log,single,120,2026-10-12
+Ash Log,single,140,2026-10-14
+Ash Log,stack,1000,2026-10-14
+Beastmen's Seal,single,500,2026-08-01
+"Rock Salt, chunk",single,10,2026-10-14
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	observations, err := Load(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(observations) != 7 {
+		t.Fatalf("Expected 7 observations, but got %d", len(observations))
+	}
+
+	now, _ := time.Parse(dateLayout, "2026-10-19")
+	table := NewTable(observations, Rules{Now: now})
+
+	// The 2026-10-01 sale is outside the window of the newest sale
+	price, ok := table.Price("Ash Log")
+	if !ok || price != 140 {
+		t.Errorf("Expected the median 140, but got %v (%v)", price, ok)
+	}
+	quote, _ := table.Quote("ash_log")
+	if quote.Stack == nil || *quote.Stack != 1000 || quote.Observations != 5 {
+		t.Errorf("Unexpected quote %+v", quote)
+	}
+
+	// Seal prices are stale
+	if _, ok := table.Price("Beastmen's Seal"); ok {
+		t.Error("Expected no price for a stale observation")
+	}
+
+	catalog := item.NewCatalog()
+	table.Attach(catalog)
+	entry, ok := catalog.Lookup("Ash Log")
+	if !ok || entry.Market == nil || *entry.Market.Single != 140 || entry.Market.Date != "2026-10-14" {
+		t.Errorf("Expected the quote on the catalog, but got %+v", entry)
+	}
+}
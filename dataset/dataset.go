@@ -0,0 +1,119 @@
+// Package dataset loads every transformed dataset for the exporters.
+package dataset
+
+import (
+	"ffxi/harvestpoints"
+	"ffxi/item"
+	"ffxi/merchants"
+	"ffxi/mobdrops"
+	"ffxi/recipe"
+	"fmt"
+	"path/filepath"
+	"sort"
+)
+
+// Sources lists transformer output files by dataset. Entries may be glob
+// patterns, e.g. merchants/merchants/*.json.
+type Sources struct {
+	Recipes       []string
+	Merchants     []string
+	Mobs          []string
+	HarvestPoints []string
+}
+
+// DefaultSources are where the CLI writes each dataset by default.
+var DefaultSources = Sources{
+	Recipes:       []string{"all_craft.json"},
+	Merchants:     []string{"merchants/merchants/*.json"},
+	Mobs:          []string{"mobdrops/*_output.json"},
+	HarvestPoints: []string{"harvestpoints/allHarvestPoints_output/*.json"},
+}
+
+// Dataset holds the records of every transformer.
+type Dataset struct {
+	Recipes       []recipe.CraftingRecipe
+	Merchants     []merchants.MerchantInfo
+	Mobs          []mobdrops.MobInfo
+	HarvestPoints []harvestpoints.HarvestPoint
+}
+
+// Load reads every source file. Recipe files may hold a single recipe or a
+// list of them.
+func Load(sources Sources) (*Dataset, error) {
+	d := &Dataset{}
+	err := loadAll(sources.Recipes, func(path string) error {
+		var recipes []recipe.CraftingRecipe
+		err := item.LoadList(path, &recipes)
+		d.Recipes = append(d.Recipes, recipes...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = loadAll(sources.Merchants, func(path string) error {
+		var merchantList []merchants.MerchantInfo
+		err := item.LoadList(path, &merchantList)
+		d.Merchants = append(d.Merchants, merchantList...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = loadAll(sources.Mobs, func(path string) error {
+		var mobs []mobdrops.MobInfo
+		if err := item.LoadList(path, &mobs); err != nil {
+			return err
+		}
+		// Other files matching the pattern, e.g. scrape rows, decode into
+		// empty mobs rather than failing
+		for i, mob := range mobs {
+			if mob.Name == "" || mob.ZoneName == "" {
+				return fmt.Errorf("mob %d has no Name or ZoneName, is this a zone drops file?", i)
+			}
+		}
+		d.Mobs = append(d.Mobs, mobs...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = loadAll(sources.HarvestPoints, func(path string) error {
+		var points []harvestpoints.HarvestPoint
+		err := item.LoadList(path, &points)
+		d.HarvestPoints = append(d.HarvestPoints, points...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// loadAll expands the patterns and loads each file in sorted order. Patterns
+// matching nothing are skipped so a missing dataset just stays empty.
+func loadAll(patterns []string, load func(path string) error) error {
+	for _, pattern := range patterns {
+		paths, err := filepath.Glob(pattern)
+		if err != nil {
+			return err
+		}
+		sort.Strings(paths)
+		for _, path := range paths {
+			if err := load(path); err != nil {
+				return fmt.Errorf("%s: %v", path, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Catalog joins the items of every dataset.
+func (d *Dataset) Catalog() *item.Catalog {
+	catalog := item.NewCatalog()
+	catalog.AddRecipes(d.Recipes)
+	catalog.AddMerchants(d.Merchants)
+	catalog.AddMobs(d.Mobs)
+	catalog.AddGatheringPoints(d.HarvestPoints)
+	return catalog
+}
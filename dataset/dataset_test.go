@@ -0,0 +1,36 @@
+package dataset
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadMobs(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"Valkurm_Dunes_output.json": `[{"Name": "Bogy", "ZoneName": "Valkurm_Dunes", "ItemDrops": [{"Name": "Bloody Robe"}]}]`,
+		"merged_drops.json":         `[{"Name": "Bloody Robe", "NPC_Name": "Bogy", "Chance": "39.2%"}]`,
+		"scrape_output.json":        `[{"NPC_Name": "Bogy", "Chance": "39.2%"}]`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	d, err := Load(Sources{Mobs: []string{filepath.Join(dir, "*_output.json")}})
+	if err == nil {
+		t.Fatalf("Expected mobs without a name or zone to be rejected, but got %+v", d.Mobs)
+	}
+
+	os.Remove(filepath.Join(dir, "scrape_output.json"))
+	d, err = Load(Sources{Mobs: []string{filepath.Join(dir, "*_output.json")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The merged scrape doesn't match the zone file pattern
+	if len(d.Mobs) != 1 || d.Mobs[0].Name != "Bogy" {
+		t.Errorf("Expected only Bogy, but got %+v", d.Mobs)
+	}
+}
@@ -0,0 +1,76 @@
+package dataset
+
+import (
+	"encoding/json"
+	"ffxi/harvestpoints"
+	"ffxi/merchants"
+	"ffxi/migrate"
+	"ffxi/mobdrops"
+	"ffxi/recipe"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LoadTree reads every transformer output file under dir, whatever its
+// layout, telling datasets apart by their content. Files of older schema
+// versions are upgraded in memory so trees of different builds compare.
+// Files that aren't outputs, and merged drop scrapes, are skipped.
+func LoadTree(dir string) (*Dataset, error) {
+	d := &Dataset{}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || strings.ToLower(filepath.Ext(path)) != ".json" {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		data, result, err := migrate.Migrate(data)
+		if err == migrate.ErrUnknownFormat {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+
+		switch result.Kind {
+		case "recipe":
+			var recipes []recipe.CraftingRecipe
+			err = decodeList(data, &recipes)
+			d.Recipes = append(d.Recipes, recipes...)
+		case "merchants":
+			var merchantList []merchants.MerchantInfo
+			err = decodeList(data, &merchantList)
+			d.Merchants = append(d.Merchants, merchantList...)
+		case "drops":
+			var mobs []mobdrops.MobInfo
+			err = decodeList(data, &mobs)
+			d.Mobs = append(d.Mobs, mobs...)
+		case "harvest":
+			var points []harvestpoints.HarvestPoint
+			err = decodeList(data, &points)
+			d.HarvestPoints = append(d.HarvestPoints, points...)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		return nil
+	})
+	return d, err
+}
+
+// decodeList decodes a JSON list into v like item.LoadList, accepting a
+// single object as a list of one.
+func decodeList(data []byte, v interface{}) error {
+	trimmed := strings.TrimSpace(string(data))
+	if strings.HasPrefix(trimmed, "{") {
+		trimmed = "[" + trimmed + "]"
+	}
+	return json.Unmarshal([]byte(trimmed), v)
+}
@@ -0,0 +1,490 @@
+// Package diff compares two builds of the datasets by entity rather than by
+// text, so a scraper refresh shows what actually changed.
+package diff
+
+import (
+	"ffxi/dataset"
+	"ffxi/harvestpoints"
+	"ffxi/item"
+	"ffxi/merchants"
+	"ffxi/mobdrops"
+	"ffxi/recipe"
+	"ffxi/zone"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Entity kinds of a Change.
+const (
+	KindRecipe   = "recipe"
+	KindMerchant = "merchant"
+	KindMob      = "mob"
+	KindHarvest  = "harvest"
+)
+
+// What happened to an entity.
+const (
+	Added   = "added"
+	Removed = "removed"
+	Changed = "changed"
+)
+
+// kinds in report order with their section titles.
+var kinds = []struct {
+	kind  string
+	title string
+}{
+	{KindRecipe, "Recipes"},
+	{KindMerchant, "Merchants"},
+	{KindMob, "Mobs"},
+	{KindHarvest, "Gathering points"},
+}
+
+// DefaultThreshold is the drop and harvest rate shift, in percentage points,
+// reported by default.
+const DefaultThreshold = 5.0
+
+// Options tune what counts as a change.
+type Options struct {
+	// Threshold is the smallest drop or harvest rate shift reported, in
+	// percentage points. Rates turning known or unknown are always reported.
+	Threshold float64
+}
+
+// Change is one difference between the builds. Field, Old and New are only
+// set for changes to an entity, e.g. Field "Bronze Cap price" with Old
+// "154-174" and New "160-180".
+type Change struct {
+	Kind   string `json:"Kind"`
+	Entity string `json:"Entity"`
+	Change string `json:"Change"`
+	Field  string `json:"Field,omitempty"`
+	Old    string `json:"Old,omitempty"`
+	New    string `json:"New,omitempty"`
+}
+
+// Report lists every change, sorted by kind, entity and field.
+type Report struct {
+	Changes []Change `json:"Changes"`
+}
+
+// Compare returns the changes from the old to the new build.
+func Compare(oldTree, newTree *dataset.Dataset, opts Options) Report {
+	report := Report{Changes: []Change{}}
+	compareRecipes(&report, oldTree.Recipes, newTree.Recipes)
+	compareMerchants(&report, oldTree.Merchants, newTree.Merchants)
+	compareMobs(&report, oldTree.Mobs, newTree.Mobs, opts)
+	compareHarvestPoints(&report, oldTree.HarvestPoints, newTree.HarvestPoints, opts)
+
+	order := make(map[string]int)
+	for i, k := range kinds {
+		order[k.kind] = i
+	}
+	sort.SliceStable(report.Changes, func(i, j int) bool {
+		a, b := report.Changes[i], report.Changes[j]
+		if a.Kind != b.Kind {
+			return order[a.Kind] < order[b.Kind]
+		}
+		if a.Entity != b.Entity {
+			return a.Entity < b.Entity
+		}
+		return a.Field < b.Field
+	})
+	return report
+}
+
+func (r *Report) add(kind, entity, change string) {
+	r.Changes = append(r.Changes, Change{Kind: kind, Entity: entity, Change: change})
+}
+
+func (r *Report) changed(kind, entity, field, oldValue, newValue string) {
+	if oldValue != newValue {
+		r.Changes = append(r.Changes, Change{Kind: kind, Entity: entity, Change: Changed, Field: field, Old: oldValue, New: newValue})
+	}
+}
+
+// keys returns the keys of both maps sorted, so entities are compared in a
+// stable order.
+func keys(a, b map[string]string) []string {
+	seen := make(map[string]bool)
+	var all []string
+	for _, m := range []map[string]string{a, b} {
+		for k := range m {
+			if !seen[k] {
+				seen[k] = true
+				all = append(all, k)
+			}
+		}
+	}
+	sort.Strings(all)
+	return all
+}
+
+// recipeKey identifies a recipe by what it makes and from what, so a changed
+// level cap or main craft is a change rather than a removal and an addition.
+func recipeKey(r recipe.CraftingRecipe) string {
+	var ingredients []string
+	for _, ingredient := range r.RequiredItems {
+		ingredients = append(ingredients, fmt.Sprintf("%d %s", ingredient.Count, item.Key(ingredient.Name)))
+	}
+	sort.Strings(ingredients)
+	return strings.Join([]string{item.Key(r.Result), strings.ToLower(r.Crystal), strings.Join(ingredients, ", ")}, "|")
+}
+
+func recipeEntity(r recipe.CraftingRecipe) string {
+	var ingredients []string
+	for _, ingredient := range r.RequiredItems {
+		ingredients = append(ingredients, fmt.Sprintf("%d %s", ingredient.Count, ingredient.Name))
+	}
+	return fmt.Sprintf("%s %s from %s", r.MainCraft, r.Result, strings.Join(ingredients, ", "))
+}
+
+func compareRecipes(report *Report, oldRecipes, newRecipes []recipe.CraftingRecipe) {
+	oldByKey, oldNames := indexRecipes(oldRecipes)
+	newByKey, newNames := indexRecipes(newRecipes)
+
+	for _, key := range keys(oldNames, newNames) {
+		o, inOld := oldByKey[key]
+		n, inNew := newByKey[key]
+		switch {
+		case !inNew:
+			report.add(KindRecipe, recipeEntity(o), Removed)
+		case !inOld:
+			report.add(KindRecipe, recipeEntity(n), Added)
+		default:
+			entity := recipeEntity(n)
+			report.changed(KindRecipe, entity, "main craft", o.MainCraft, n.MainCraft)
+			var crafts []string
+			for craft := range o.SkillLevels {
+				crafts = append(crafts, craft)
+			}
+			for craft := range n.SkillLevels {
+				if _, ok := o.SkillLevels[craft]; !ok {
+					crafts = append(crafts, craft)
+				}
+			}
+			sort.Strings(crafts)
+			for _, craft := range crafts {
+				report.changed(KindRecipe, entity, craft+" level", levelText(o.SkillLevels, craft), levelText(n.SkillLevels, craft))
+			}
+			report.changed(KindRecipe, entity, "results", resultsText(o.AllPossibleResults), resultsText(n.AllPossibleResults))
+			report.changed(KindRecipe, entity, "required tools", o.RequiredTools, n.RequiredTools)
+		}
+	}
+}
+
+func indexRecipes(recipes []recipe.CraftingRecipe) (map[string]recipe.CraftingRecipe, map[string]string) {
+	byKey := make(map[string]recipe.CraftingRecipe)
+	names := make(map[string]string)
+	for _, r := range recipes {
+		key := recipeKey(r)
+		// Recipe files and all_craft.json hold the same recipes
+		if _, ok := byKey[key]; !ok {
+			byKey[key] = r
+			names[key] = r.Name
+		}
+	}
+	return byKey, names
+}
+
+func levelText(skillLevels map[string]int, craft string) string {
+	if level, ok := skillLevels[craft]; ok {
+		return strconv.Itoa(level)
+	}
+	return ""
+}
+
+func resultsText(results []recipe.ResultsIncludingHighQuality) string {
+	var parts []string
+	for _, result := range results {
+		quality := "NQ"
+		if result.HighQualityLevel > 0 {
+			quality = fmt.Sprintf("HQ%d", result.HighQualityLevel)
+		}
+		parts = append(parts, fmt.Sprintf("%s %d %s", quality, result.Count, result.Name))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ", ")
+}
+
+func zoneName(id string) string {
+	if z, ok := zone.Lookup(id); ok {
+		return z.Name
+	}
+	return strings.ReplaceAll(id, "_", " ")
+}
+
+func merchantEntity(merchant merchants.MerchantInfo) string {
+	return fmt.Sprintf("%s in %s", merchant.Name, zoneName(zone.ID(merchant.Zone)))
+}
+
+func compareMerchants(report *Report, oldMerchants, newMerchants []merchants.MerchantInfo) {
+	oldByKey, oldNames := indexMerchants(oldMerchants)
+	newByKey, newNames := indexMerchants(newMerchants)
+
+	for _, key := range keys(oldNames, newNames) {
+		o, inOld := oldByKey[key]
+		n, inNew := newByKey[key]
+		switch {
+		case !inNew:
+			report.add(KindMerchant, merchantEntity(o), Removed)
+		case !inOld:
+			report.add(KindMerchant, merchantEntity(n), Added)
+		default:
+			entity := merchantEntity(n)
+			oldGoods := make(map[string]merchants.ItemInfo)
+			oldItems := make(map[string]string)
+			for _, good := range o.Items {
+				oldGoods[item.Key(good.Name)] = good
+				oldItems[item.Key(good.Name)] = good.Name
+			}
+			newGoods := make(map[string]merchants.ItemInfo)
+			newItems := make(map[string]string)
+			for _, good := range n.Items {
+				newGoods[item.Key(good.Name)] = good
+				newItems[item.Key(good.Name)] = good.Name
+			}
+			for _, itemKey := range keys(oldItems, newItems) {
+				oldGood, sold := oldGoods[itemKey]
+				newGood, sells := newGoods[itemKey]
+				switch {
+				case !sells:
+					report.changed(KindMerchant, entity, oldGood.Name, priceText(oldGood), "")
+				case !sold:
+					report.changed(KindMerchant, entity, newGood.Name, "", priceText(newGood))
+				default:
+					report.changed(KindMerchant, entity, newGood.Name+" price", priceText(oldGood), priceText(newGood))
+					report.changed(KindMerchant, entity, newGood.Name+" rank", oldGood.RankRequirement, newGood.RankRequirement)
+				}
+			}
+		}
+	}
+}
+
+func indexMerchants(merchantList []merchants.MerchantInfo) (map[string]merchants.MerchantInfo, map[string]string) {
+	byKey := make(map[string]merchants.MerchantInfo)
+	names := make(map[string]string)
+	for _, merchant := range merchantList {
+		key := strings.ToLower(merchant.Name) + "|" + zone.ID(merchant.Zone)
+		if _, ok := byKey[key]; !ok {
+			byKey[key] = merchant
+			names[key] = merchant.Name
+		}
+	}
+	return byKey, names
+}
+
+func priceText(good merchants.ItemInfo) string {
+	if good.MinPrice == good.MaxPrice {
+		return fmt.Sprintf("%d gil", good.MinPrice)
+	}
+	return fmt.Sprintf("%d-%d gil", good.MinPrice, good.MaxPrice)
+}
+
+func mobEntity(mob mobdrops.MobInfo) string {
+	return fmt.Sprintf("%s in %s", mob.Name, zoneName(zone.ID(mob.ZoneName)))
+}
+
+func compareMobs(report *Report, oldMobs, newMobs []mobdrops.MobInfo, opts Options) {
+	oldByKey, oldNames := indexMobs(oldMobs)
+	newByKey, newNames := indexMobs(newMobs)
+
+	for _, key := range keys(oldNames, newNames) {
+		o, inOld := oldByKey[key]
+		n, inNew := newByKey[key]
+		switch {
+		case !inNew:
+			report.add(KindMob, mobEntity(o), Removed)
+		case !inOld:
+			report.add(KindMob, mobEntity(n), Added)
+		default:
+			entity := mobEntity(n)
+			report.changed(KindMob, entity, "level", levelRangeText(o.LevelRange), levelRangeText(n.LevelRange))
+
+			oldDrops := make(map[string]mobdrops.ItemDrop)
+			oldItems := make(map[string]string)
+			for _, drop := range o.ItemDrops {
+				oldDrops[item.Key(drop.Name)] = drop
+				oldItems[item.Key(drop.Name)] = drop.Name
+			}
+			newDrops := make(map[string]mobdrops.ItemDrop)
+			newItems := make(map[string]string)
+			for _, drop := range n.ItemDrops {
+				newDrops[item.Key(drop.Name)] = drop
+				newItems[item.Key(drop.Name)] = drop.Name
+			}
+			for _, itemKey := range keys(oldItems, newItems) {
+				oldDrop, dropped := oldDrops[itemKey]
+				newDrop, drops := newDrops[itemKey]
+				switch {
+				case !drops:
+					report.changed(KindMob, entity, oldDrop.Name, percentText(oldDrop.Percent), "")
+				case !dropped:
+					report.changed(KindMob, entity, newDrop.Name, "", percentText(newDrop.Percent))
+				case rateShifted(oldDrop.Percent, newDrop.Percent, opts.Threshold):
+					report.changed(KindMob, entity, newDrop.Name+" drop rate", percentText(oldDrop.Percent), percentText(newDrop.Percent))
+				}
+			}
+		}
+	}
+}
+
+func indexMobs(mobs []mobdrops.MobInfo) (map[string]mobdrops.MobInfo, map[string]string) {
+	byKey := make(map[string]mobdrops.MobInfo)
+	names := make(map[string]string)
+	for _, mob := range mobs {
+		key := strings.ToLower(mob.Name) + "|" + zone.ID(mob.ZoneName)
+		if _, ok := byKey[key]; !ok {
+			byKey[key] = mob
+			names[key] = mob.Name
+		}
+	}
+	return byKey, names
+}
+
+func levelRangeText(levelRange *mobdrops.LevelRange) string {
+	if levelRange == nil {
+		return "unknown"
+	}
+	return fmt.Sprintf("%d-%d", levelRange.Min, levelRange.Max)
+}
+
+func percentText(percent *float64) string {
+	if percent == nil {
+		return "unknown"
+	}
+	return strconv.FormatFloat(math.Round(*percent*100)/100, 'f', -1, 64) + "%"
+}
+
+// rateShifted reports whether a rate moved by at least threshold percentage
+// points or turned known or unknown.
+func rateShifted(oldRate, newRate *float64, threshold float64) bool {
+	if oldRate == nil || newRate == nil {
+		return (oldRate == nil) != (newRate == nil)
+	}
+	shift := math.Abs(*newRate - *oldRate)
+	return shift > 0 && shift >= threshold
+}
+
+func harvestEntity(point harvestpoints.HarvestPoint) string {
+	return fmt.Sprintf("%s in %s", point.Name, zoneName(zone.ID(point.ZoneName)))
+}
+
+func compareHarvestPoints(report *Report, oldPoints, newPoints []harvestpoints.HarvestPoint, opts Options) {
+	oldByKey, oldNames := indexHarvestPoints(oldPoints)
+	newByKey, newNames := indexHarvestPoints(newPoints)
+
+	for _, key := range keys(oldNames, newNames) {
+		o, inOld := oldByKey[key]
+		n, inNew := newByKey[key]
+		switch {
+		case !inNew:
+			report.add(KindHarvest, harvestEntity(o), Removed)
+		case !inOld:
+			report.add(KindHarvest, harvestEntity(n), Added)
+		default:
+			entity := harvestEntity(n)
+			report.changed(KindHarvest, entity, "required tool", o.RequiredTool, n.RequiredTool)
+
+			oldYields := make(map[string]harvestpoints.ItemDropInfo)
+			oldItems := make(map[string]string)
+			for _, info := range o.ItemDropInfos {
+				oldYields[item.Key(info.FriendlyName)] = info
+				oldItems[item.Key(info.FriendlyName)] = item.DisplayName(info.FriendlyName)
+			}
+			newYields := make(map[string]harvestpoints.ItemDropInfo)
+			newItems := make(map[string]string)
+			for _, info := range n.ItemDropInfos {
+				newYields[item.Key(info.FriendlyName)] = info
+				newItems[item.Key(info.FriendlyName)] = item.DisplayName(info.FriendlyName)
+			}
+			for _, itemKey := range keys(oldItems, newItems) {
+				oldYield, yielded := oldYields[itemKey]
+				newYield, yields := newYields[itemKey]
+				oldPercent, newPercent := o.YieldPercent(oldYield), n.YieldPercent(newYield)
+				switch {
+				case !yields:
+					report.changed(KindHarvest, entity, oldItems[itemKey], yieldText(oldYield, oldPercent), "")
+				case !yielded:
+					report.changed(KindHarvest, entity, newItems[itemKey], "", yieldText(newYield, newPercent))
+				default:
+					report.changed(KindHarvest, entity, newItems[itemKey]+" tier", oldYield.Tier, newYield.Tier)
+					if rateShifted(oldPercent, newPercent, opts.Threshold) {
+						report.changed(KindHarvest, entity, newItems[itemKey]+" rate", percentText(oldPercent), percentText(newPercent))
+					}
+				}
+			}
+		}
+	}
+}
+
+func indexHarvestPoints(points []harvestpoints.HarvestPoint) (map[string]harvestpoints.HarvestPoint, map[string]string) {
+	byKey := make(map[string]harvestpoints.HarvestPoint)
+	names := make(map[string]string)
+	for _, point := range points {
+		key := string(point.PointType) + "|" + zone.ID(point.ZoneName)
+		if _, ok := byKey[key]; !ok {
+			byKey[key] = point
+			names[key] = point.Name
+		}
+	}
+	return byKey, names
+}
+
+func yieldText(info harvestpoints.ItemDropInfo, percent *float64) string {
+	if info.Tier == "" {
+		return percentText(percent)
+	}
+	return fmt.Sprintf("%s %s", info.Tier, percentText(percent))
+}
+
+// WriteText writes the report for people, one section per kind with a count
+// line and one line per change: + added, - removed and ~ changed.
+func (r Report) WriteText(w io.Writer) error {
+	if len(r.Changes) == 0 {
+		_, err := fmt.Fprintln(w, "No changes")
+		return err
+	}
+
+	for _, k := range kinds {
+		var changes []Change
+		counts := make(map[string]int)
+		entities := make(map[string]bool)
+		for _, change := range r.Changes {
+			if change.Kind != k.kind {
+				continue
+			}
+			changes = append(changes, change)
+			if change.Change != Changed {
+				counts[change.Change]++
+			} else if !entities[change.Entity] {
+				entities[change.Entity] = true
+				counts[Changed]++
+			}
+		}
+		if len(changes) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(w, "%s: %d added, %d removed, %d changed\n", k.title, counts[Added], counts[Removed], counts[Changed])
+		for _, change := range changes {
+			switch {
+			case change.Change == Added:
+				fmt.Fprintf(w, "  + %s\n", change.Entity)
+			case change.Change == Removed:
+				fmt.Fprintf(w, "  - %s\n", change.Entity)
+			case change.Old == "":
+				fmt.Fprintf(w, "  ~ %s: %s added (%s)\n", change.Entity, change.Field, change.New)
+			case change.New == "":
+				fmt.Fprintf(w, "  ~ %s: %s removed (was %s)\n", change.Entity, change.Field, change.Old)
+			default:
+				fmt.Fprintf(w, "  ~ %s: %s %s -> %s\n", change.Entity, change.Field, change.Old, change.New)
+			}
+		}
+	}
+	return nil
+}
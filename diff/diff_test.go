@@ -0,0 +1,264 @@
+package diff
+
+import (
+	"bytes"
+	"ffxi/dataset"
+	"ffxi/harvestpoints"
+	"ffxi/merchants"
+	"ffxi/mobdrops"
+	"ffxi/recipe"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func percent(p float64) *float64 {
+	return &p
+}
+
+func oldDataset() *dataset.Dataset {
+	return &dataset.Dataset{
+		Recipes: []recipe.CraftingRecipe{
+			{
+				Name:          "Woodworking-7-Ash Lumber-From-1-Ash Log",
+				Crystal:       "Wind Crystal",
+				RequiredItems: []recipe.Item{{Name: "Ash Log", Count: 1}},
+				SkillLevels:   map[string]int{"Woodworking": 7},
+				Result:        "Ash Lumber",
+				MainCraft:     "Woodworking",
+			},
+			{
+				Name:          "Smithing-3-Bronze Ingot-From-4-Bronze Sheet",
+				Crystal:       "Fire Crystal",
+				RequiredItems: []recipe.Item{{Name: "Bronze Sheet", Count: 4}},
+				SkillLevels:   map[string]int{"Smithing": 3},
+				Result:        "Bronze Ingot",
+				MainCraft:     "Smithing",
+			},
+		},
+		Merchants: []merchants.MerchantInfo{
+			{
+				Name: "Dahjal",
+				Zone: "Port_Bastok",
+				Items: []merchants.ItemInfo{
+					{Name: "Ash Log", MinPrice: 90, MaxPrice: 110},
+					{Name: "Bronze Cap", MinPrice: 154, MaxPrice: 174},
+				},
+			},
+		},
+		Mobs: []mobdrops.MobInfo{
+			{
+				Name:       "Bogy",
+				LevelRange: &mobdrops.LevelRange{Min: 18, Max: 21},
+				ZoneName:   "Valkurm_Dunes",
+				ItemDrops: []mobdrops.ItemDrop{
+					{Name: "Bloody Robe", Percent: percent(39.2)},
+					{Name: "Ash Log", Percent: percent(10)},
+					{Name: "Gil", Percent: percent(50)},
+				},
+			},
+		},
+		HarvestPoints: []harvestpoints.HarvestPoint{
+			{
+				Name:      "Logging Point",
+				PointType: harvestpoints.Logging,
+				ZoneName:  "Giddeus",
+				ItemDropInfos: []harvestpoints.ItemDropInfo{
+					{Name: "Ash Log", FriendlyName: "Ash_Log", Percent: percent(15.1), Tier: "Common"},
+				},
+			},
+		},
+	}
+}
+
+func newDataset() *dataset.Dataset {
+	return &dataset.Dataset{
+		Recipes: []recipe.CraftingRecipe{
+			{
+				Name:          "Woodworking-8-Ash Lumber-From-1-Ash Log",
+				Crystal:       "Wind Crystal",
+				RequiredItems: []recipe.Item{{Name: "Ash Log", Count: 1}},
+				SkillLevels:   map[string]int{"Woodworking": 8},
+				Result:        "Ash Lumber",
+				MainCraft:     "Woodworking",
+			},
+			{
+				Name:          "Woodworking-9-Arrowwood Lumber-From-1-Arrowwood Log",
+				Crystal:       "Wind Crystal",
+				RequiredItems: []recipe.Item{{Name: "Arrowwood Log", Count: 1}},
+				SkillLevels:   map[string]int{"Woodworking": 9},
+				Result:        "Arrowwood Lumber",
+				MainCraft:     "Woodworking",
+			},
+		},
+		Merchants: []merchants.MerchantInfo{
+			{
+				Name: "Dahjal",
+				Zone: "Port Bastok",
+				Items: []merchants.ItemInfo{
+					{Name: "Ash Log", MinPrice: 90, MaxPrice: 110},
+					{Name: "Bronze Cap", MinPrice: 160, MaxPrice: 180},
+					{Name: "Maple Log", MinPrice: 60, MaxPrice: 60},
+				},
+			},
+		},
+		Mobs: []mobdrops.MobInfo{
+			{
+				Name:       "Bogy",
+				LevelRange: &mobdrops.LevelRange{Min: 18, Max: 21},
+				ZoneName:   "Valkurm_Dunes",
+				ItemDrops: []mobdrops.ItemDrop{
+					{Name: "bloody robe", Percent: percent(41)},
+					{Name: "ash log", Percent: nil},
+					{Name: "Gil", Percent: percent(60)},
+				},
+			},
+		},
+		HarvestPoints: []harvestpoints.HarvestPoint{
+			{
+				Name:      "Logging Point",
+				PointType: harvestpoints.Logging,
+				ZoneName:  "Giddeus",
+				ItemDropInfos: []harvestpoints.ItemDropInfo{
+					{Name: "Ash Log", FriendlyName: "Ash_Log", Percent: percent(8.2), Tier: "Uncommon"},
+				},
+			},
+			{
+				Name:      "Mining Point",
+				PointType: harvestpoints.Mining,
+				ZoneName:  "Zeruhn_Mines",
+			},
+		},
+	}
+}
+
+func TestCompare(t *testing.T) {
+	report := Compare(oldDataset(), newDataset(), Options{Threshold: DefaultThreshold})
+
+	expected := []Change{
+		{Kind: KindRecipe, Entity: "Smithing Bronze Ingot from 4 Bronze Sheet", Change: Removed},
+		{Kind: KindRecipe, Entity: "Woodworking Arrowwood Lumber from 1 Arrowwood Log", Change: Added},
+		{Kind: KindRecipe, Entity: "Woodworking Ash Lumber from 1 Ash Log", Change: Changed, Field: "Woodworking level", Old: "7", New: "8"},
+		{Kind: KindMerchant, Entity: "Dahjal in Port Bastok", Change: Changed, Field: "Bronze Cap price", Old: "154-174 gil", New: "160-180 gil"},
+		{Kind: KindMerchant, Entity: "Dahjal in Port Bastok", Change: Changed, Field: "Maple Log", New: "60 gil"},
+		{Kind: KindMob, Entity: "Bogy in Valkurm Dunes", Change: Changed, Field: "Gil drop rate", Old: "50%", New: "60%"},
+		{Kind: KindMob, Entity: "Bogy in Valkurm Dunes", Change: Changed, Field: "ash log drop rate", Old: "10%", New: "unknown"},
+		{Kind: KindHarvest, Entity: "Logging Point in Giddeus", Change: Changed, Field: "Ash Log rate", Old: "15.1%", New: "8.2%"},
+		{Kind: KindHarvest, Entity: "Logging Point in Giddeus", Change: Changed, Field: "Ash Log tier", Old: "Common", New: "Uncommon"},
+		{Kind: KindHarvest, Entity: "Mining Point in Zeruhn Mines", Change: Added},
+	}
+	if !reflect.DeepEqual(report.Changes, expected) {
+		t.Errorf("Expected changes:\n%+v\nbut got:\n%+v", expected, report.Changes)
+	}
+
+	report = Compare(oldDataset(), oldDataset(), Options{Threshold: DefaultThreshold})
+	if len(report.Changes) != 0 {
+		t.Errorf("Expected no changes between equal datasets, but got %+v", report.Changes)
+	}
+}
+
+func TestCompareMainCraft(t *testing.T) {
+	oldTree := &dataset.Dataset{Recipes: []recipe.CraftingRecipe{{
+		Crystal:       "Fire Crystal",
+		RequiredItems: []recipe.Item{{Name: "Copper Ore", Count: 4}},
+		SkillLevels:   map[string]int{"Smithing": 2, "Goldsmithing": 2},
+		Result:        "Copper Ingot",
+		MainCraft:     "Smithing",
+	}}}
+	newTree := &dataset.Dataset{Recipes: []recipe.CraftingRecipe{oldTree.Recipes[0]}}
+	newTree.Recipes[0].MainCraft = "Goldsmithing"
+
+	report := Compare(oldTree, newTree, Options{Threshold: DefaultThreshold})
+	expected := []Change{
+		{Kind: KindRecipe, Entity: "Goldsmithing Copper Ingot from 4 Copper Ore", Change: Changed, Field: "main craft", Old: "Smithing", New: "Goldsmithing"},
+	}
+	if !reflect.DeepEqual(report.Changes, expected) {
+		t.Errorf("Expected changes:\n%+v\nbut got:\n%+v", expected, report.Changes)
+	}
+}
+
+func TestRateShifted(t *testing.T) {
+	testCases := []struct {
+		name      string
+		old       *float64
+		new       *float64
+		threshold float64
+		expected  bool
+	}{
+		{"below threshold", percent(39.2), percent(41), 5, false},
+		{"at threshold", percent(10), percent(15), 5, true},
+		{"turned unknown", percent(10), nil, 5, true},
+		{"turned known", nil, percent(10), 5, true},
+		{"both unknown", nil, nil, 5, false},
+		{"any shift without threshold", percent(10), percent(10.5), 0, true},
+		{"unchanged without threshold", percent(10), percent(10), 0, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if shifted := rateShifted(tc.old, tc.new, tc.threshold); shifted != tc.expected {
+				t.Errorf("Expected %v, but got %v", tc.expected, shifted)
+			}
+		})
+	}
+}
+
+func TestWriteText(t *testing.T) {
+	report := Compare(oldDataset(), newDataset(), Options{Threshold: DefaultThreshold})
+	var buf bytes.Buffer
+	if err := report.WriteText(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `Recipes: 1 added, 1 removed, 1 changed
+  - Smithing Bronze Ingot from 4 Bronze Sheet
+  + Woodworking Arrowwood Lumber from 1 Arrowwood Log
+  ~ Woodworking Ash Lumber from 1 Ash Log: Woodworking level 7 -> 8
+Merchants: 0 added, 0 removed, 1 changed
+  ~ Dahjal in Port Bastok: Bronze Cap price 154-174 gil -> 160-180 gil
+  ~ Dahjal in Port Bastok: Maple Log added (60 gil)
+Mobs: 0 added, 0 removed, 1 changed
+  ~ Bogy in Valkurm Dunes: Gil drop rate 50% -> 60%
+  ~ Bogy in Valkurm Dunes: ash log drop rate 10% -> unknown
+Gathering points: 1 added, 0 removed, 1 changed
+  ~ Logging Point in Giddeus: Ash Log rate 15.1% -> 8.2%
+  ~ Logging Point in Giddeus: Ash Log tier Common -> Uncommon
+  + Mining Point in Zeruhn Mines
+`
+	if buf.String() != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, buf.String())
+	}
+}
+
+func TestLoadTree(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"all_craft.json":                  `[{"Crystal": "Wind Crystal", "RequiredItems": [{"Name": "Ash Log", "Count": 1}], "SkillLevels": {"Woodworking": 7}, "Result": "Ash Lumber", "Name": "Ash Lumber-From-1Ash Log"}]`,
+		"merchants/Port_Bastok.json":      `[{"Name": "Dahjal", "Zone": "Port_Bastok", "Items": [{"Name": "Ash Log", "MinPrice": 90, "MaxPrice": 110, "RankRequirement": ""}]}]`,
+		"harvestpoints/Giddeus.json":      `[{"Name": "Logging Point", "ZoneName": "Giddeus", "ItemDropInfos": []}]`,
+		"notes.json":                      `{"Todo": "more zones"}`,
+		"recipes/Woodworking/single.json": `{"Crystal": "Wind Crystal", "RequiredItems": [{"Name": "Maple Log", "Count": 1}], "SkillLevels": {"Woodworking": 5}, "Result": "Maple Lumber", "Name": "Maple Lumber-From-1Maple Log"}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	d, err := dataset.LoadTree(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Recipes) != 2 || len(d.Merchants) != 1 || len(d.HarvestPoints) != 1 || len(d.Mobs) != 0 {
+		t.Fatalf("Expected 2 recipes, 1 merchant and 1 point, but got %+v", d)
+	}
+	// Old files are upgraded so they line up with current builds
+	if d.Recipes[0].MainCraft != "Woodworking" || d.HarvestPoints[0].PointType != harvestpoints.Logging {
+		t.Errorf("Expected upgraded records, but got %+v and %+v", d.Recipes[0], d.HarvestPoints[0])
+	}
+}
@@ -0,0 +1,538 @@
+package export
+
+import (
+	"encoding/json"
+	"ffxi/dataset"
+	"ffxi/harvestpoints"
+	"ffxi/item"
+	"ffxi/merchants"
+	"ffxi/mobdrops"
+	"ffxi/recipe"
+	"ffxi/zone"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Kinds of RAG documents. The first group are entities, the second the
+// relationships between them.
+const (
+	DocItem      = "item"
+	DocRecipe    = "recipe"
+	DocMerchant  = "merchant"
+	DocMob       = "mob"
+	DocZone      = "zone"
+	DocGathering = "gathering"
+
+	DocIngredient = "ingredient"
+	DocSells      = "sells"
+	DocDrops      = "drops"
+	DocYields     = "yields"
+)
+
+// Document is one knowledge document for retrieval: a natural-language Text
+// to embed and structured Metadata to filter on. IDs only depend on the
+// entities a document describes, so re-exporting a changed dataset updates
+// documents in place instead of duplicating them.
+type Document struct {
+	ID       string                 `json:"ID"`
+	Kind     string                 `json:"Kind"`
+	Text     string                 `json:"Text"`
+	Metadata map[string]interface{} `json:"Metadata"`
+}
+
+// WriteRAG writes the dataset as JSON lines of Documents: one per item,
+// recipe, merchant, mob, zone and gathering point, then one per recipe
+// ingredient, merchant good, mob drop and gathering yield.
+func WriteRAG(w io.Writer, d *dataset.Dataset) error {
+	encoder := json.NewEncoder(w)
+	for _, doc := range Documents(d) {
+		if err := encoder.Encode(doc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Documents builds the RAG documents of a dataset in a stable order.
+func Documents(d *dataset.Dataset) []Document {
+	r := &ragBuilder{d: d, catalog: d.Catalog(), used: make(map[string]bool)}
+	for _, rec := range d.Recipes {
+		r.catalog.Add(rec.Result)
+	}
+
+	for _, entry := range r.catalog.Entries() {
+		r.item(entry)
+	}
+	for _, rec := range d.Recipes {
+		r.recipe(rec)
+	}
+	for _, merchant := range d.Merchants {
+		r.merchant(merchant)
+	}
+	for _, mob := range d.Mobs {
+		r.mob(mob)
+	}
+	for _, point := range d.HarvestPoints {
+		r.gathering(point)
+	}
+	r.zones()
+	return r.docs
+}
+
+// ragBuilder collects documents and makes their IDs unique.
+type ragBuilder struct {
+	d       *dataset.Dataset
+	catalog *item.Catalog
+	docs    []Document
+	used    map[string]bool
+}
+
+var nonSlugRe = regexp.MustCompile(`[^a-z0-9]+`)
+
+func ragSlug(s string) string {
+	return strings.Trim(nonSlugRe.ReplaceAllString(strings.ToLower(s), "-"), "-")
+}
+
+// docID returns kind/part/... with each part slugged, e.g.
+// merchant/brunhilde/bastok-markets.
+func docID(kind string, parts ...string) string {
+	id := kind
+	for _, part := range parts {
+		id += "/" + ragSlug(part)
+	}
+	return id
+}
+
+// add appends a document, giving an ID seen before a numbered suffix.
+func (r *ragBuilder) add(id, kind, text string, metadata map[string]interface{}) {
+	unique := id
+	for i := 2; r.used[unique]; i++ {
+		unique = id + "-" + strconv.Itoa(i)
+	}
+	r.used[unique] = true
+	metadata["Kind"] = kind
+	r.docs = append(r.docs, Document{ID: unique, Kind: kind, Text: text, Metadata: metadata})
+}
+
+// itemName returns the catalog spelling of an item.
+func (r *ragBuilder) itemName(name string) string {
+	if entry, ok := r.catalog.Lookup(name); ok {
+		return entry.Name
+	}
+	return item.DisplayName(name)
+}
+
+func itemID(name string) string {
+	return docID(DocItem, item.Key(name))
+}
+
+func recipeID(rec recipe.CraftingRecipe) string {
+	return docID(DocRecipe, rec.Name)
+}
+
+func merchantDocID(merchant merchants.MerchantInfo) string {
+	return docID(DocMerchant, merchant.Name, zone.ID(merchant.Zone))
+}
+
+func mobDocID(mob mobdrops.MobInfo) string {
+	return docID(DocMob, mob.Name, zone.ID(mob.ZoneName))
+}
+
+func gatheringID(point harvestpoints.HarvestPoint) string {
+	return docID(DocGathering, string(point.PointType), zone.ID(point.ZoneName))
+}
+
+// count returns "1 recipe" or "3 recipes".
+func count(n int, singular, plural string) string {
+	if n == 1 {
+		return "1 " + singular
+	}
+	return strconv.Itoa(n) + " " + plural
+}
+
+// recipeCount counts the distinct recipes of refs. MadeBy holds a ref per
+// result tier, so a recipe with HQ results is listed several times.
+func recipeCount(refs []item.RecipeRef) int {
+	recipes := make(map[string]bool)
+	for _, ref := range refs {
+		recipes[ref.Recipe] = true
+	}
+	return len(recipes)
+}
+
+// article returns "an" before words starting with a vowel, "a" otherwise.
+func article(word string) string {
+	if word != "" && strings.ContainsRune("AEIOUaeiou", rune(word[0])) {
+		return "an"
+	}
+	return "a"
+}
+
+// joinAnd joins a list as "a, b and c".
+func joinAnd(parts []string) string {
+	if len(parts) < 2 {
+		return strings.Join(parts, "")
+	}
+	return strings.Join(parts[:len(parts)-1], ", ") + " and " + parts[len(parts)-1]
+}
+
+func formatGil(min, max int) string {
+	if min == max {
+		return fmt.Sprintf("%d gil", min)
+	}
+	return fmt.Sprintf("%d-%d gil", min, max)
+}
+
+// merchantPlace returns "Bastok Markets (F-10)", or the zone alone when the
+// position is not known.
+func merchantPlace(merchant merchants.MerchantInfo) string {
+	if merchant.Position == "" {
+		return zoneName(zone.ID(merchant.Zone))
+	}
+	return fmt.Sprintf("%s (%s)", zoneName(zone.ID(merchant.Zone)), merchant.Position)
+}
+
+// crystalElement returns "Wind" for both "Wind" and "Wind Crystal".
+func crystalElement(crystal string) string {
+	return strings.TrimSuffix(item.CrystalName(crystal), " Crystal")
+}
+
+func (r *ragBuilder) item(entry *item.Entry) {
+	var sources []string
+	if n := recipeCount(entry.MadeBy); n > 0 {
+		sources = append(sources, "made by "+count(n, "recipe", "recipes"))
+	}
+	if n := recipeCount(entry.UsedBy); n > 0 {
+		sources = append(sources, "used in "+count(n, "recipe", "recipes"))
+	}
+	if n := len(entry.Vendors); n > 0 {
+		cheapest := entry.Vendors[0].MinPrice
+		for _, vendor := range entry.Vendors {
+			if vendor.MinPrice < cheapest {
+				cheapest = vendor.MinPrice
+			}
+		}
+		sources = append(sources, fmt.Sprintf("sold by %s from %d gil", count(n, "merchant", "merchants"), cheapest))
+	}
+	if n := len(entry.Mobs); n > 0 {
+		sources = append(sources, "dropped by "+count(n, "mob", "mobs"))
+	}
+	if n := len(entry.GatheringPoints); n > 0 {
+		sources = append(sources, "gathered at "+count(n, "gathering point", "gathering points"))
+	}
+
+	text := entry.Name + " is an item."
+	if len(sources) > 0 {
+		text = fmt.Sprintf("%s is %s.", entry.Name, joinAnd(sources))
+	}
+	if entry.Market != nil && entry.Market.Single != nil {
+		text += fmt.Sprintf(" It sells for about %.0f gil on the auction house.", *entry.Market.Single)
+	}
+
+	// ObtainedBy leaves out crafting, which the catalog knows from MadeBy
+	obtainedBy := r.catalog.ObtainedBy(entry.Name)
+	if len(entry.MadeBy) > 0 {
+		obtainedBy = append([]string{recipe.ObtainedCrafted}, obtainedBy...)
+	}
+	metadata := map[string]interface{}{
+		"Name":            entry.Name,
+		"Aliases":         entry.Aliases,
+		"ObtainedBy":      obtainedBy,
+		"MadeBy":          recipeCount(entry.MadeBy),
+		"UsedBy":          recipeCount(entry.UsedBy),
+		"Vendors":         len(entry.Vendors),
+		"Mobs":            len(entry.Mobs),
+		"GatheringPoints": len(entry.GatheringPoints),
+	}
+	if entry.ItemDBID > 0 {
+		metadata["ItemDBID"] = entry.ItemDBID
+	}
+	r.add(itemID(entry.Name), DocItem, text, metadata)
+}
+
+func (r *ragBuilder) recipe(rec recipe.CraftingRecipe) {
+	level := rec.SkillLevels[rec.MainCraft]
+	var ingredients []string
+	for _, ingredient := range rec.RequiredItems {
+		ingredients = append(ingredients, fmt.Sprintf("%d %s", ingredient.Count, r.itemName(ingredient.Name)))
+	}
+	text := fmt.Sprintf("%s is %s %s %d %s synthesis from %s.", r.itemName(rec.Result), article(rec.MainCraft), rec.MainCraft, level,
+		crystalElement(rec.Crystal), joinAnd(ingredients))
+
+	var subcrafts []string
+	for craft, craftLevel := range rec.SkillLevels {
+		if craft != rec.MainCraft {
+			subcrafts = append(subcrafts, fmt.Sprintf("%s %d", craft, craftLevel))
+		}
+	}
+	sort.Strings(subcrafts)
+	if len(subcrafts) > 0 {
+		text += " It also requires " + joinAnd(subcrafts) + "."
+	}
+	if rec.RequiredTools != "" {
+		text += " Required tools: " + rec.RequiredTools + "."
+	}
+	var highQuality []string
+	for _, result := range rec.AllPossibleResults {
+		if result.HighQualityLevel > 0 {
+			highQuality = append(highQuality, fmt.Sprintf("HQ%d gives %d %s", result.HighQualityLevel, result.Count, r.itemName(result.Name)))
+		}
+	}
+	if len(highQuality) > 0 {
+		text += " " + strings.Join(highQuality, ", ") + "."
+	}
+
+	var ingredientNames []string
+	for _, ingredient := range rec.RequiredItems {
+		ingredientNames = append(ingredientNames, r.itemName(ingredient.Name))
+	}
+	r.add(recipeID(rec), DocRecipe, text, map[string]interface{}{
+		"Name":        rec.Name,
+		"Result":      r.itemName(rec.Result),
+		"ResultID":    itemID(rec.Result),
+		"Craft":       rec.MainCraft,
+		"Level":       level,
+		"SkillLevels": rec.SkillLevels,
+		"Crystal":     crystalElement(rec.Crystal),
+		"Ingredients": ingredientNames,
+		"Results":     rec.AllPossibleResults,
+	})
+
+	for _, ingredient := range rec.RequiredItems {
+		name := r.itemName(ingredient.Name)
+		r.add(docID(DocIngredient, rec.Name, item.Key(ingredient.Name)), DocIngredient,
+			fmt.Sprintf("%d %s is used to craft %s (%s %d).", ingredient.Count, name, r.itemName(rec.Result), rec.MainCraft, level),
+			map[string]interface{}{
+				"Item":     name,
+				"ItemID":   itemID(ingredient.Name),
+				"Recipe":   rec.Name,
+				"RecipeID": recipeID(rec),
+				"Craft":    rec.MainCraft,
+				"Level":    level,
+				"Count":    ingredient.Count,
+			})
+	}
+}
+
+func (r *ragBuilder) merchant(merchant merchants.MerchantInfo) {
+	place := merchantPlace(merchant)
+	var goods []string
+	for _, good := range merchant.Items {
+		goods = append(goods, fmt.Sprintf("%s for %s", r.itemName(good.Name), formatGil(good.MinPrice, good.MaxPrice)))
+	}
+	text := fmt.Sprintf("%s in %s sells %s.", merchant.Name, place, joinAnd(goods))
+	if len(goods) == 0 {
+		text = fmt.Sprintf("%s is a merchant in %s.", merchant.Name, place)
+	}
+
+	id := merchantDocID(merchant)
+	r.add(id, DocMerchant, text, map[string]interface{}{
+		"Name":     merchant.Name,
+		"Zone":     zone.ID(merchant.Zone),
+		"ZoneName": zoneName(zone.ID(merchant.Zone)),
+		"Position": merchant.Position,
+		"Goods":    len(merchant.Items),
+	})
+
+	for _, good := range merchant.Items {
+		name := r.itemName(good.Name)
+		text := fmt.Sprintf("%s in %s sells %s for %s.", merchant.Name, place, name, formatGil(good.MinPrice, good.MaxPrice))
+		if good.RankRequirement != "" {
+			text = strings.TrimSuffix(text, ".") + fmt.Sprintf(", requiring rank %s.", good.RankRequirement)
+		}
+		r.add(docID(DocSells, merchant.Name, zone.ID(merchant.Zone), item.Key(good.Name)), DocSells, text, map[string]interface{}{
+			"Item":            name,
+			"ItemID":          itemID(good.Name),
+			"Merchant":        merchant.Name,
+			"MerchantID":      id,
+			"Zone":            zone.ID(merchant.Zone),
+			"MinPrice":        good.MinPrice,
+			"MaxPrice":        good.MaxPrice,
+			"RankRequirement": good.RankRequirement,
+		})
+	}
+}
+
+// dropRate describes a drop rate, e.g. "39.2% (652 drops in 1665 kills)".
+func dropRate(drop mobdrops.ItemDrop) string {
+	if drop.Percent == nil {
+		return "an unknown rate"
+	}
+	rate := formatPercent(drop.Percent)
+	if drop.AmountDefeated > 0 {
+		rate += fmt.Sprintf(" (%d drops in %d kills)", drop.AmountDropped, drop.AmountDefeated)
+	}
+	if drop.SourceZone != "" {
+		rate += fmt.Sprintf(" as measured in %s", zoneName(drop.SourceZone))
+	}
+	return rate
+}
+
+func (r *ragBuilder) mob(mob mobdrops.MobInfo) {
+	zoneID := zone.ID(mob.ZoneName)
+	text := fmt.Sprintf("%s is a level %s mob in %s.", mob.Name, formatLevels(mob.LevelRange), zoneName(zoneID))
+	if mob.LevelRange == nil {
+		text = fmt.Sprintf("%s is a mob of unknown level in %s.", mob.Name, zoneName(zoneID))
+	}
+	var drops []string
+	for _, drop := range mob.ItemDrops {
+		drops = append(drops, fmt.Sprintf("%s (%s)", r.itemName(drop.Name), formatPercent(drop.Percent)))
+	}
+	if len(drops) > 0 {
+		text += " It drops " + joinAnd(drops) + "."
+	}
+
+	metadata := map[string]interface{}{
+		"Name":     mob.Name,
+		"Zone":     zoneID,
+		"ZoneName": zoneName(zoneID),
+		"Drops":    len(mob.ItemDrops),
+	}
+	if mob.LevelRange != nil {
+		metadata["MinLevel"] = mob.LevelRange.Min
+		metadata["MaxLevel"] = mob.LevelRange.Max
+	}
+	id := mobDocID(mob)
+	r.add(id, DocMob, text, metadata)
+
+	for _, drop := range mob.ItemDrops {
+		name := r.itemName(drop.Name)
+		r.add(docID(DocDrops, mob.Name, zoneID, item.Key(drop.Name)), DocDrops,
+			fmt.Sprintf("%s in %s drops %s at %s.", mob.Name, zoneName(zoneID), name, dropRate(drop)),
+			map[string]interface{}{
+				"Item":           name,
+				"ItemID":         itemID(drop.Name),
+				"Mob":            mob.Name,
+				"MobID":          id,
+				"Zone":           zoneID,
+				"Percent":        drop.Percent,
+				"Source":         drop.Source,
+				"AmountDropped":  drop.AmountDropped,
+				"AmountDefeated": drop.AmountDefeated,
+			})
+	}
+}
+
+func yieldName(info harvestpoints.ItemDropInfo) string {
+	if info.FriendlyName != "" {
+		return info.FriendlyName
+	}
+	return info.Name
+}
+
+// yieldRate describes a yield, e.g. "Common, 15.1%".
+func yieldRate(point harvestpoints.HarvestPoint, info harvestpoints.ItemDropInfo) string {
+	rate := formatPercent(point.YieldPercent(info))
+	if info.Tier != "" {
+		rate = strings.ReplaceAll(info.Tier, "_", " ") + ", " + rate
+	}
+	return rate
+}
+
+func (r *ragBuilder) gathering(point harvestpoints.HarvestPoint) {
+	zoneID := zone.ID(point.ZoneName)
+	var yields []string
+	for _, info := range point.ItemDropInfos {
+		yields = append(yields, fmt.Sprintf("%s (%s)", r.itemName(yieldName(info)), yieldRate(point, info)))
+	}
+	text := fmt.Sprintf("%s has a %s", zoneName(zoneID), point.Name)
+	if point.RequiredTool != "" {
+		text += fmt.Sprintf(" worked with a %s", point.RequiredTool)
+	}
+	if len(yields) > 0 {
+		text += " yielding " + joinAnd(yields)
+	}
+	text += "."
+
+	id := gatheringID(point)
+	r.add(id, DocGathering, text, map[string]interface{}{
+		"Name":         point.Name,
+		"PointType":    string(point.PointType),
+		"RequiredTool": point.RequiredTool,
+		"Zone":         zoneID,
+		"ZoneName":     zoneName(zoneID),
+		"Yields":       len(point.ItemDropInfos),
+	})
+
+	for _, info := range point.ItemDropInfos {
+		name := r.itemName(yieldName(info))
+		text := fmt.Sprintf("%s can be gathered from a %s in %s", name, point.Name, zoneName(zoneID))
+		if point.RequiredTool != "" {
+			text += fmt.Sprintf(" with a %s", point.RequiredTool)
+		}
+		text += fmt.Sprintf(" (%s).", yieldRate(point, info))
+		r.add(docID(DocYields, string(point.PointType), zoneID, item.Key(yieldName(info))), DocYields, text, map[string]interface{}{
+			"Item":         name,
+			"ItemID":       itemID(yieldName(info)),
+			"GatheringID":  id,
+			"PointType":    string(point.PointType),
+			"RequiredTool": point.RequiredTool,
+			"Zone":         zoneID,
+			"Percent":      point.YieldPercent(info),
+			"Tier":         info.Tier,
+		})
+	}
+}
+
+func (r *ragBuilder) zones() {
+	merchantCount := make(map[string]int)
+	mobCount := make(map[string]int)
+	pointCount := make(map[string]int)
+	for _, merchant := range r.d.Merchants {
+		merchantCount[zone.ID(merchant.Zone)]++
+	}
+	for _, mob := range r.d.Mobs {
+		mobCount[zone.ID(mob.ZoneName)]++
+	}
+	for _, point := range r.d.HarvestPoints {
+		pointCount[zone.ID(point.ZoneName)]++
+	}
+
+	seen := make(map[string]bool)
+	var ids []string
+	for _, counts := range []map[string]int{merchantCount, mobCount, pointCount} {
+		for id := range counts {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		region := ""
+		if z, ok := zone.Lookup(id); ok {
+			region = z.Region
+		}
+
+		var contents []string
+		if n := merchantCount[id]; n > 0 {
+			contents = append(contents, count(n, "merchant", "merchants"))
+		}
+		if n := mobCount[id]; n > 0 {
+			contents = append(contents, count(n, "mob", "mobs"))
+		}
+		if n := pointCount[id]; n > 0 {
+			contents = append(contents, count(n, "gathering point", "gathering points"))
+		}
+		text := zoneName(id) + " is a zone"
+		if region != "" {
+			text += " in " + region
+		}
+		text += " with " + joinAnd(contents) + "."
+
+		r.add(docID(DocZone, id), DocZone, text, map[string]interface{}{
+			"ID":              id,
+			"Name":            zoneName(id),
+			"Region":          region,
+			"Merchants":       merchantCount[id],
+			"Mobs":            mobCount[id],
+			"GatheringPoints": pointCount[id],
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestDocuments(t *testing.T) {
+	d := testDataset()
+	d.Merchants[0].Position = "K-6"
+	docs := Documents(d)
+
+	texts := make(map[string]string)
+	for _, doc := range docs {
+		if _, ok := texts[doc.ID]; ok {
+			t.Errorf("Duplicate document ID %s", doc.ID)
+		}
+		texts[doc.ID] = doc.Text
+	}
+
+	testCases := []struct {
+		id       string
+		expected string
+	}{
+		{"recipe/woodworking-7-ash-lumber-from-1-ash-log", "Ash Lumber is a Woodworking 7 Wind synthesis from 1 Ash Log. HQ1 gives 2 Ash Lumber."},
+		{"merchant/ostalie/southern-san-doria", "Ostalie in Southern San d'Oria (K-6) sells San d'Orian Grape for 60-70 gil."},
+		{"mob/bogy/valkurm-dunes", "Bogy is a level 18-21 mob in Valkurm Dunes. It drops Bloody Robe (39.2%) and Ash Log (unknown)."},
+		{"zone/valkurm-dunes", "Valkurm Dunes is a zone in Zulkheim with 1 mob."},
+		{"item/ash-log", "Ash Log is used in 1 recipe and dropped by 1 mob."},
+		{"item/ash-lumber", "Ash Lumber is made by 1 recipe."},
+		{"ingredient/woodworking-7-ash-lumber-from-1-ash-log/ash-log", "1 Ash Log is used to craft Ash Lumber (Woodworking 7)."},
+		{"drops/bogy/valkurm-dunes/bloody-robe", "Bogy in Valkurm Dunes drops Bloody Robe at 39.2% (652 drops in 1665 kills)."},
+		{"drops/bogy/valkurm-dunes/ash-log", "Bogy in Valkurm Dunes drops Ash Log at an unknown rate."},
+	}
+	for _, tc := range testCases {
+		if texts[tc.id] != tc.expected {
+			t.Errorf("Expected %s to be %q, but got %q", tc.id, tc.expected, texts[tc.id])
+		}
+	}
+
+	for _, doc := range docs {
+		if doc.ID == "item/ash-lumber" && !reflect.DeepEqual(doc.Metadata["ObtainedBy"], []string{"crafted"}) {
+			t.Errorf("Expected Ash Lumber to be obtained by crafting, but got %v", doc.Metadata["ObtainedBy"])
+		}
+	}
+
+	// IDs must not change between exports so documents can be re-indexed
+	if !reflect.DeepEqual(docs, Documents(d)) {
+		t.Errorf("Expected the same documents from the same dataset")
+	}
+}
+
+func TestArticle(t *testing.T) {
+	testCases := []struct {
+		word     string
+		expected string
+	}{
+		{"Alchemy", "an"},
+		{"Woodworking", "a"},
+		{"", "a"},
+	}
+	for _, tc := range testCases {
+		if result := article(tc.word); result != tc.expected {
+			t.Errorf("Expected %q before %q, but got %q", tc.expected, tc.word, result)
+		}
+	}
+}
+
+func TestWriteRAG(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteRAG(&buf, testDataset()); err != nil {
+		t.Fatal(err)
+	}
+
+	scanner := bufio.NewScanner(&buf)
+	lines := 0
+	for scanner.Scan() {
+		var doc Document
+		if err := json.Unmarshal(scanner.Bytes(), &doc); err != nil {
+			t.Fatalf("Line %d is not a document: %v", lines+1, err)
+		}
+		if doc.ID == "" || doc.Text == "" || doc.Metadata["Kind"] != doc.Kind {
+			t.Errorf("Incomplete document %+v", doc)
+		}
+		lines++
+	}
+	if lines != len(Documents(testDataset())) {
+		t.Errorf("Expected one line per document, but got %d lines", lines)
+	}
+}
@@ -0,0 +1,309 @@
+// Package export writes the transformed datasets in formats for other tools.
+package export
+
+import (
+	"bufio"
+	"ffxi/dataset"
+	"ffxi/item"
+	"ffxi/zone"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// schema is the normalized relational schema of WriteSQL, in dependency
+// order. It sticks to types and syntax SQLite and Postgres share.
+var schema = []struct {
+	table   string
+	columns []string
+}{
+	{"zones", []string{
+		"id TEXT PRIMARY KEY",
+		"name TEXT NOT NULL",
+		"region TEXT",
+	}},
+	{"items", []string{
+		"id INTEGER PRIMARY KEY",
+		"name TEXT NOT NULL UNIQUE",
+		"itemdb_id INTEGER",
+	}},
+	{"item_aliases", []string{
+		"item_id INTEGER NOT NULL REFERENCES items(id)",
+		"alias TEXT NOT NULL",
+	}},
+	{"recipes", []string{
+		"id INTEGER PRIMARY KEY",
+		"name TEXT NOT NULL",
+		"result_item_id INTEGER NOT NULL REFERENCES items(id)",
+		"crystal_item_id INTEGER REFERENCES items(id)",
+		"main_craft TEXT NOT NULL",
+		"level INTEGER NOT NULL",
+		"required_tools TEXT",
+	}},
+	{"recipe_skills", []string{
+		"recipe_id INTEGER NOT NULL REFERENCES recipes(id)",
+		"craft TEXT NOT NULL",
+		"level INTEGER NOT NULL",
+	}},
+	{"recipe_ingredients", []string{
+		"recipe_id INTEGER NOT NULL REFERENCES recipes(id)",
+		"item_id INTEGER NOT NULL REFERENCES items(id)",
+		"count INTEGER NOT NULL",
+	}},
+	{"recipe_results", []string{
+		"recipe_id INTEGER NOT NULL REFERENCES recipes(id)",
+		"item_id INTEGER NOT NULL REFERENCES items(id)",
+		"count INTEGER NOT NULL",
+		"hq_level INTEGER NOT NULL",
+	}},
+	{"merchants", []string{
+		"id INTEGER PRIMARY KEY",
+		"name TEXT NOT NULL",
+		"zone_id TEXT NOT NULL REFERENCES zones(id)",
+	}},
+	{"merchant_goods", []string{
+		"merchant_id INTEGER NOT NULL REFERENCES merchants(id)",
+		"item_id INTEGER NOT NULL REFERENCES items(id)",
+		"min_price INTEGER NOT NULL",
+		"max_price INTEGER NOT NULL",
+		"rank_requirement TEXT",
+	}},
+	{"mobs", []string{
+		"id INTEGER PRIMARY KEY",
+		"name TEXT NOT NULL",
+		"zone_id TEXT NOT NULL REFERENCES zones(id)",
+		"min_level INTEGER",
+		"max_level INTEGER",
+	}},
+	{"mob_drops", []string{
+		"mob_id INTEGER NOT NULL REFERENCES mobs(id)",
+		"item_id INTEGER NOT NULL REFERENCES items(id)",
+		"percent REAL",
+		"source TEXT NOT NULL",
+		"source_zone_id TEXT REFERENCES zones(id)",
+		"amount_dropped INTEGER NOT NULL",
+		"amount_defeated INTEGER NOT NULL",
+		"rate_conflict INTEGER NOT NULL",
+	}},
+	{"harvest_points", []string{
+		"id INTEGER PRIMARY KEY",
+		"name TEXT NOT NULL",
+		"point_type TEXT",
+		"required_tool TEXT",
+		"zone_id TEXT NOT NULL REFERENCES zones(id)",
+	}},
+	{"harvest_yields", []string{
+		"point_id INTEGER NOT NULL REFERENCES harvest_points(id)",
+		"item_id INTEGER NOT NULL REFERENCES items(id)",
+		"total_known_drops INTEGER NOT NULL",
+		"percent REAL",
+		"tier TEXT",
+	}},
+}
+
+// WriteSQL writes the dataset as a SQL dump of CREATE TABLE and INSERT
+// statements. Items are joined across datasets through the item catalog and
+// zones through the zone registry, so every row refers to them by key.
+func WriteSQL(w io.Writer, d *dataset.Dataset) error {
+	out := bufio.NewWriter(w)
+	dump := &sqlDump{out: out, catalog: d.Catalog(), zones: make(map[string]bool)}
+	for _, r := range d.Recipes {
+		// The result is usually, but not always, among AllPossibleResults
+		dump.catalog.Add(r.Result)
+	}
+
+	fmt.Fprintln(out, "-- FFXI data dump")
+	fmt.Fprintln(out, "BEGIN;")
+	for i := len(schema) - 1; i >= 0; i-- {
+		fmt.Fprintf(out, "DROP TABLE IF EXISTS %s;\n", schema[i].table)
+	}
+	for _, table := range schema {
+		fmt.Fprintf(out, "\nCREATE TABLE %s (\n  %s\n);\n", table.table, strings.Join(table.columns, ",\n  "))
+	}
+
+	dump.writeZones(d)
+	dump.writeItems()
+	dump.writeRecipes(d)
+	dump.writeMerchants(d)
+	dump.writeMobs(d)
+	dump.writeHarvestPoints(d)
+
+	fmt.Fprintln(out, "\nCOMMIT;")
+	return out.Flush()
+}
+
+type sqlDump struct {
+	out     *bufio.Writer
+	catalog *item.Catalog
+	itemIDs map[*item.Entry]int
+	zones   map[string]bool
+}
+
+func (s *sqlDump) insert(table string, values ...string) {
+	fmt.Fprintf(s.out, "INSERT INTO %s VALUES (%s);\n", table, strings.Join(values, ", "))
+}
+
+// writeZones inserts every zone the datasets refer to.
+func (s *sqlDump) writeZones(d *dataset.Dataset) {
+	var names []string
+	for _, merchant := range d.Merchants {
+		names = append(names, merchant.Zone)
+	}
+	for _, mob := range d.Mobs {
+		names = append(names, mob.ZoneName)
+		for _, drop := range mob.ItemDrops {
+			if drop.SourceZone != "" {
+				names = append(names, drop.SourceZone)
+			}
+		}
+	}
+	for _, point := range d.HarvestPoints {
+		names = append(names, point.ZoneName)
+	}
+
+	var ids []string
+	for _, name := range names {
+		id := zone.ID(name)
+		if !s.zones[id] {
+			s.zones[id] = true
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	fmt.Fprintln(s.out)
+	for _, id := range ids {
+		z, ok := zone.Lookup(id)
+		if !ok {
+			z = zone.Zone{ID: id, Name: strings.ReplaceAll(id, "_", " ")}
+		}
+		s.insert("zones", sqlString(z.ID), sqlString(z.Name), sqlNullString(z.Region))
+	}
+}
+
+func (s *sqlDump) writeItems() {
+	s.itemIDs = make(map[*item.Entry]int)
+
+	fmt.Fprintln(s.out)
+	for i, entry := range s.catalog.Entries() {
+		id := i + 1
+		s.itemIDs[entry] = id
+		itemDBID := "NULL"
+		if entry.ItemDBID > 0 {
+			itemDBID = strconv.Itoa(entry.ItemDBID)
+		}
+		s.insert("items", strconv.Itoa(id), sqlString(entry.Name), itemDBID)
+		for _, alias := range entry.Aliases {
+			s.insert("item_aliases", strconv.Itoa(id), sqlString(alias))
+		}
+	}
+}
+
+// itemID returns the id of an item by any spelling. Every name in the
+// dataset is in the catalog.
+func (s *sqlDump) itemID(name string) string {
+	entry, _ := s.catalog.Lookup(name)
+	return strconv.Itoa(s.itemIDs[entry])
+}
+
+func (s *sqlDump) writeRecipes(d *dataset.Dataset) {
+	fmt.Fprintln(s.out)
+	for i, r := range d.Recipes {
+		id := strconv.Itoa(i + 1)
+		crystal := "NULL"
+		if r.Crystal != "" {
+			crystal = s.itemID(item.CrystalName(r.Crystal))
+		}
+		s.insert("recipes", id, sqlString(r.Name), s.itemID(r.Result), crystal,
+			sqlString(r.MainCraft), strconv.Itoa(r.SkillLevels[r.MainCraft]), sqlNullString(r.RequiredTools))
+
+		var crafts []string
+		for craft := range r.SkillLevels {
+			crafts = append(crafts, craft)
+		}
+		sort.Strings(crafts)
+		for _, craft := range crafts {
+			s.insert("recipe_skills", id, sqlString(craft), strconv.Itoa(r.SkillLevels[craft]))
+		}
+		for _, ingredient := range r.RequiredItems {
+			s.insert("recipe_ingredients", id, s.itemID(ingredient.Name), strconv.Itoa(ingredient.Count))
+		}
+		for _, result := range r.AllPossibleResults {
+			s.insert("recipe_results", id, s.itemID(result.Name), strconv.Itoa(result.Count), strconv.Itoa(result.HighQualityLevel))
+		}
+	}
+}
+
+func (s *sqlDump) writeMerchants(d *dataset.Dataset) {
+	fmt.Fprintln(s.out)
+	for i, merchant := range d.Merchants {
+		id := strconv.Itoa(i + 1)
+		s.insert("merchants", id, sqlString(merchant.Name), sqlString(zone.ID(merchant.Zone)))
+		for _, good := range merchant.Items {
+			s.insert("merchant_goods", id, s.itemID(good.Name), strconv.Itoa(good.MinPrice), strconv.Itoa(good.MaxPrice), sqlNullString(good.RankRequirement))
+		}
+	}
+}
+
+func (s *sqlDump) writeMobs(d *dataset.Dataset) {
+	fmt.Fprintln(s.out)
+	for i, mob := range d.Mobs {
+		id := strconv.Itoa(i + 1)
+		minLevel, maxLevel := "NULL", "NULL"
+		if mob.LevelRange != nil {
+			minLevel, maxLevel = strconv.Itoa(mob.LevelRange.Min), strconv.Itoa(mob.LevelRange.Max)
+		}
+		s.insert("mobs", id, sqlString(mob.Name), sqlString(zone.ID(mob.ZoneName)), minLevel, maxLevel)
+		for _, drop := range mob.ItemDrops {
+			sourceZone := "NULL"
+			if drop.SourceZone != "" {
+				sourceZone = sqlString(zone.ID(drop.SourceZone))
+			}
+			s.insert("mob_drops", id, s.itemID(drop.Name), sqlFloat(drop.Percent), sqlString(drop.Source), sourceZone,
+				strconv.Itoa(drop.AmountDropped), strconv.Itoa(drop.AmountDefeated), sqlBool(drop.RateConflict))
+		}
+	}
+}
+
+func (s *sqlDump) writeHarvestPoints(d *dataset.Dataset) {
+	fmt.Fprintln(s.out)
+	for i, point := range d.HarvestPoints {
+		id := strconv.Itoa(i + 1)
+		s.insert("harvest_points", id, sqlString(point.Name), sqlNullString(string(point.PointType)),
+			sqlNullString(point.RequiredTool), sqlString(zone.ID(point.ZoneName)))
+		for _, yield := range point.ItemDropInfos {
+			name := yield.FriendlyName
+			if name == "" {
+				name = yield.Name
+			}
+			s.insert("harvest_yields", id, s.itemID(name), strconv.Itoa(yield.TotalKnownDrops), sqlFloat(yield.Percent), sqlNullString(yield.Tier))
+		}
+	}
+}
+
+func sqlString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+func sqlNullString(s string) string {
+	if s == "" {
+		return "NULL"
+	}
+	return sqlString(s)
+}
+
+func sqlFloat(f *float64) string {
+	if f == nil {
+		return "NULL"
+	}
+	return strconv.FormatFloat(*f, 'f', -1, 64)
+}
+
+func sqlBool(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
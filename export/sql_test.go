@@ -0,0 +1,70 @@
+package export
+
+import (
+	"bytes"
+	"ffxi/dataset"
+	"ffxi/merchants"
+	"ffxi/mobdrops"
+	"ffxi/recipe"
+	"strings"
+	"testing"
+)
+
+func testDataset() *dataset.Dataset {
+	percent := 39.2
+	return &dataset.Dataset{
+		Recipes: []recipe.CraftingRecipe{{
+			Name:          "Woodworking-7-Ash Lumber-From-1-Ash Log",
+			Result:        "Ash Lumber",
+			Crystal:       "Wind",
+			MainCraft:     "Woodworking",
+			SkillLevels:   map[string]int{"Woodworking": 7},
+			RequiredItems: []recipe.Item{{Name: "Ash Log", Count: 1}},
+			AllPossibleResults: []recipe.ResultsIncludingHighQuality{
+				{Name: "Ash Lumber", Count: 1},
+				{Name: "Ash Lumber", Count: 2, HighQualityLevel: 1},
+			},
+		}},
+		Merchants: []merchants.MerchantInfo{
+			{Name: "Ostalie", Zone: "Southern_San_dOria", Items: []merchants.ItemInfo{{Name: "San d'Orian Grape", MinPrice: 60, MaxPrice: 70}}},
+		},
+		Mobs: []mobdrops.MobInfo{
+			{Name: "Bogy", ZoneName: "Valkurm_Dunes", LevelRange: &mobdrops.LevelRange{Min: 18, Max: 21}, ItemDrops: []mobdrops.ItemDrop{
+				{Name: "bloody robe", Percent: &percent, Source: mobdrops.SourceScrape, AmountDropped: 652, AmountDefeated: 1665},
+				{Name: "ash log", Source: mobdrops.SourceUnknown},
+			}},
+		},
+	}
+}
+
+func TestWriteSQL(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteSQL(&buf, testDataset()); err != nil {
+		t.Fatal(err)
+	}
+	dump := buf.String()
+
+	// Items are numbered by name: Ash Log, Ash Lumber, Bloody Robe, San d'Orian Grape, Wind Crystal
+	expected := []string{
+		"CREATE TABLE mob_drops (",
+		"INSERT INTO items VALUES (1, 'Ash Log', NULL);",
+		"INSERT INTO item_aliases VALUES (1, 'ash log');",
+		"INSERT INTO recipes VALUES (1, 'Woodworking-7-Ash Lumber-From-1-Ash Log', 2, 5, 'Woodworking', 7, NULL);",
+		"INSERT INTO recipe_results VALUES (1, 2, 2, 1);",
+		"INSERT INTO zones VALUES ('Southern_San_dOria', 'Southern San d''Oria', 'San d''Oria');",
+		"INSERT INTO merchant_goods VALUES (1, 4, 60, 70, NULL);",
+		"INSERT INTO mobs VALUES (1, 'Bogy', 'Valkurm_Dunes', 18, 21);",
+		"INSERT INTO mob_drops VALUES (1, 3, 39.2, 'scrape', NULL, 652, 1665, 0);",
+		"INSERT INTO mob_drops VALUES (1, 1, NULL, 'unknown', NULL, 0, 0, 0);",
+	}
+	for _, statement := range expected {
+		if !strings.Contains(dump, statement) {
+			t.Errorf("Expected %s in the dump", statement)
+		}
+	}
+
+	// Tables are dropped children first so reloading the dump works
+	if strings.Index(dump, "DROP TABLE IF EXISTS mob_drops;") > strings.Index(dump, "DROP TABLE IF EXISTS mobs;") {
+		t.Errorf("Expected mob_drops to be dropped before mobs")
+	}
+}
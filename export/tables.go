@@ -0,0 +1,38 @@
+package export
+
+import (
+	"ffxi/dataset"
+	"ffxi/harvestpoints"
+	"ffxi/merchants"
+	"ffxi/mobdrops"
+	"ffxi/recipe"
+	"ffxi/transform"
+)
+
+// WriteTables writes the flattened datasets into dir as recipe_ingredients,
+// merchant_goods, mob_drops and harvest_yields tables in a table format of
+// the transform package, csv or tsv.
+func WriteTables(dir string, d *dataset.Dataset, format string) error {
+	opts := transform.Options{OutputDir: dir, Format: format}
+	if err := opts.CheckFormat(transform.FormatCSV, transform.FormatTSV); err != nil {
+		return err
+	}
+
+	tables := []struct {
+		name  string
+		table transform.Table
+	}{
+		{"recipe_ingredients", recipe.IngredientTable(d.Recipes)},
+		{"merchant_goods", merchants.GoodsTable(d.Merchants)},
+		{"mob_drops", mobdrops.DropTable(d.Mobs)},
+		{"harvest_yields", harvestpoints.YieldTable(d.HarvestPoints)},
+	}
+	for _, t := range tables {
+		table := t.table
+		err := opts.Write(t.name, nil, func() transform.Table { return table })
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
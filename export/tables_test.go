@@ -0,0 +1,34 @@
+package export
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteTables(t *testing.T) {
+	dir := t.TempDir()
+	if err := WriteTables(dir, testDataset(), "csv"); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		file     string
+		expected string
+	}{
+		{"recipe_ingredients.csv", "Woodworking-7-Ash Lumber-From-1-Ash Log,Ash Lumber,Woodworking,7,Wind,Ash Log,1"},
+		{"merchant_goods.csv", "Ostalie,Southern_San_dOria,San d'Orian Grape,60,70,"},
+		{"mob_drops.csv", "Bogy,Valkurm_Dunes,18,21,ash log,,unknown,,0,0,false"},
+		{"harvest_yields.csv", "Zone,Point,PointType,RequiredTool,Item,TotalKnownDrops,Percent,Tier"},
+	}
+	for _, tc := range testCases {
+		fileContent, err := os.ReadFile(filepath.Join(dir, tc.file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(fileContent), tc.expected+"\n") {
+			t.Errorf("Expected %s in %s, but got %s", tc.expected, tc.file, fileContent)
+		}
+	}
+}
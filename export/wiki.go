@@ -0,0 +1,454 @@
+package export
+
+import (
+	"bufio"
+	"ffxi/dataset"
+	"ffxi/harvestpoints"
+	"ffxi/item"
+	"ffxi/merchants"
+	"ffxi/mobdrops"
+	"ffxi/recipe"
+	"ffxi/zone"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Wiki page directories, one per kind of page.
+const (
+	wikiItems     = "items"
+	wikiRecipes   = "recipes"
+	wikiMerchants = "merchants"
+	wikiMobs      = "mobs"
+	wikiZones     = "zones"
+)
+
+// WriteWiki writes a static Markdown wiki into dir with one page per item,
+// recipe, merchant, mob and zone plus an index.md. Pages link to each other
+// with relative links, so the wiki works from any Markdown viewer.
+func WriteWiki(dir string, d *dataset.Dataset) error {
+	w := newWiki(d)
+
+	for _, kind := range []string{wikiItems, wikiRecipes, wikiMerchants, wikiMobs, wikiZones} {
+		if err := os.MkdirAll(filepath.Join(dir, kind), os.ModePerm); err != nil {
+			return err
+		}
+	}
+
+	pages := []wikiPage{{"index.md", w.writeIndex}}
+	for _, entry := range w.catalog.Entries() {
+		entry := entry
+		pages = append(pages, wikiPage{w.path(wikiItems, entry.Name), func(p *page) { w.writeItem(p, entry) }})
+	}
+	for _, r := range d.Recipes {
+		r := r
+		pages = append(pages, wikiPage{w.path(wikiRecipes, r.Name), func(p *page) { w.writeRecipe(p, r) }})
+	}
+	for _, merchant := range d.Merchants {
+		merchant := merchant
+		pages = append(pages, wikiPage{w.path(wikiMerchants, merchantID(merchant)), func(p *page) { w.writeMerchant(p, merchant) }})
+	}
+	for _, mob := range d.Mobs {
+		mob := mob
+		pages = append(pages, wikiPage{w.path(wikiMobs, mobID(mob)), func(p *page) { w.writeMob(p, mob) }})
+	}
+	for _, zoneID := range w.zoneIDs {
+		zoneID := zoneID
+		pages = append(pages, wikiPage{w.path(wikiZones, zoneID), func(p *page) { w.writeZone(p, zoneID) }})
+	}
+
+	for _, pg := range pages {
+		file, err := os.Create(filepath.Join(dir, pg.path))
+		if err != nil {
+			return err
+		}
+		p := &page{out: bufio.NewWriter(file), dir: filepath.Dir(pg.path)}
+		pg.write(p)
+		err = p.out.Flush()
+		closeErr := file.Close()
+		if err != nil {
+			return err
+		}
+		if closeErr != nil {
+			return closeErr
+		}
+	}
+	return nil
+}
+
+// wikiPage is a page to write and the function writing it.
+type wikiPage struct {
+	path  string
+	write func(p *page)
+}
+
+// wiki indexes the dataset by page so pages can link to each other.
+type wiki struct {
+	d       *dataset.Dataset
+	catalog *item.Catalog
+	// slugs maps kind and page ID to a unique file name.
+	slugs map[string]map[string]string
+	used  map[string]bool
+
+	recipesByName   map[string]recipe.CraftingRecipe
+	merchantsByZone map[string][]merchants.MerchantInfo
+	mobsByZone      map[string][]mobdrops.MobInfo
+	pointsByZone    map[string][]harvestpoints.HarvestPoint
+	zoneIDs         []string
+}
+
+func newWiki(d *dataset.Dataset) *wiki {
+	w := &wiki{
+		d:               d,
+		catalog:         d.Catalog(),
+		slugs:           make(map[string]map[string]string),
+		used:            make(map[string]bool),
+		recipesByName:   make(map[string]recipe.CraftingRecipe),
+		merchantsByZone: make(map[string][]merchants.MerchantInfo),
+		mobsByZone:      make(map[string][]mobdrops.MobInfo),
+		pointsByZone:    make(map[string][]harvestpoints.HarvestPoint),
+	}
+	for _, r := range d.Recipes {
+		w.catalog.Add(r.Result)
+		w.recipesByName[r.Name] = r
+	}
+
+	zones := make(map[string]bool)
+	for _, merchant := range d.Merchants {
+		id := zone.ID(merchant.Zone)
+		zones[id] = true
+		w.merchantsByZone[id] = append(w.merchantsByZone[id], merchant)
+	}
+	for _, mob := range d.Mobs {
+		id := zone.ID(mob.ZoneName)
+		zones[id] = true
+		w.mobsByZone[id] = append(w.mobsByZone[id], mob)
+	}
+	for _, point := range d.HarvestPoints {
+		id := zone.ID(point.ZoneName)
+		zones[id] = true
+		w.pointsByZone[id] = append(w.pointsByZone[id], point)
+	}
+	for id := range zones {
+		w.zoneIDs = append(w.zoneIDs, id)
+	}
+	sort.Strings(w.zoneIDs)
+	return w
+}
+
+var unsafeSlugRe = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// path returns the page path of a kind and ID, giving IDs that slug the same
+// a numbered suffix. IDs with nothing to slug, e.g. non-ASCII names, are
+// named page rather than hidden files.
+func (w *wiki) path(kind, id string) string {
+	if w.slugs[kind] == nil {
+		w.slugs[kind] = make(map[string]string)
+	}
+	if slug, ok := w.slugs[kind][id]; ok {
+		return filepath.Join(kind, slug)
+	}
+
+	base := strings.Trim(unsafeSlugRe.ReplaceAllString(id, "_"), "_.")
+	if len(base) > 100 {
+		base = base[:100]
+	}
+	if base == "" {
+		base = "page"
+	}
+	slug := base + ".md"
+	for i := 2; w.used[kind+"/"+slug]; i++ {
+		slug = base + "_" + strconv.Itoa(i) + ".md"
+	}
+	w.used[kind+"/"+slug] = true
+	w.slugs[kind][id] = slug
+	return filepath.Join(kind, slug)
+}
+
+func merchantID(merchant merchants.MerchantInfo) string {
+	return merchant.Name + " " + zone.ID(merchant.Zone)
+}
+
+func mobID(mob mobdrops.MobInfo) string {
+	return mob.Name + " " + zone.ID(mob.ZoneName)
+}
+
+// page is a Markdown page being written, dir is its directory in the wiki.
+type page struct {
+	out *bufio.Writer
+	dir string
+}
+
+func (p *page) line(format string, args ...interface{}) {
+	fmt.Fprintf(p.out, format+"\n", args...)
+}
+
+// link returns a relative Markdown link from the page to another page.
+func (p *page) link(text, target string) string {
+	rel, err := filepath.Rel(p.dir, target)
+	if err != nil {
+		rel = target
+	}
+	return fmt.Sprintf("[%s](%s)", escapeMarkdown(text), filepath.ToSlash(rel))
+}
+
+// table writes a Markdown table, nothing when there are no rows.
+func (p *page) table(title string, columns []string, rows [][]string) {
+	if len(rows) == 0 {
+		return
+	}
+	p.line("\n## %s\n", title)
+	p.line("| %s |", strings.Join(columns, " | "))
+	p.line("|%s", strings.Repeat(" --- |", len(columns)))
+	for _, row := range rows {
+		p.line("| %s |", strings.Join(row, " | "))
+	}
+}
+
+func escapeMarkdown(s string) string {
+	return strings.NewReplacer("|", `\|`, "[", `\[`, "]", `\]`, "*", `\*`, "_", `\_`).Replace(s)
+}
+
+func (w *wiki) itemLink(p *page, name string) string {
+	if entry, ok := w.catalog.Lookup(name); ok {
+		return p.link(entry.Name, w.path(wikiItems, entry.Name))
+	}
+	return escapeMarkdown(name)
+}
+
+func (w *wiki) recipeLink(p *page, name string) string {
+	if _, ok := w.recipesByName[name]; ok {
+		return p.link(name, w.path(wikiRecipes, name))
+	}
+	return escapeMarkdown(name)
+}
+
+func (w *wiki) zoneLink(p *page, name string) string {
+	id := zone.ID(name)
+	return p.link(zoneName(id), w.path(wikiZones, id))
+}
+
+func zoneName(id string) string {
+	if z, ok := zone.Lookup(id); ok {
+		return z.Name
+	}
+	return strings.ReplaceAll(id, "_", " ")
+}
+
+func formatPercent(percent *float64) string {
+	if percent == nil {
+		return "unknown"
+	}
+	return strconv.FormatFloat(*percent, 'f', -1, 64) + "%"
+}
+
+func formatLevels(levelRange *mobdrops.LevelRange) string {
+	if levelRange == nil {
+		return "unknown"
+	}
+	if levelRange.Min == levelRange.Max {
+		return strconv.Itoa(levelRange.Min)
+	}
+	return fmt.Sprintf("%d-%d", levelRange.Min, levelRange.Max)
+}
+
+func (w *wiki) writeIndex(p *page) {
+	p.line("# FFXI data\n")
+	p.line("%d items, %d recipes, %d merchants, %d mobs and %d zones.",
+		len(w.catalog.Entries()), len(w.d.Recipes), len(w.d.Merchants), len(w.d.Mobs), len(w.zoneIDs))
+
+	p.line("\n## Zones\n")
+	for _, id := range w.zoneIDs {
+		p.line("- %s", w.zoneLink(p, id))
+	}
+
+	crafts := make(map[string][]recipe.CraftingRecipe)
+	for _, r := range w.d.Recipes {
+		crafts[r.MainCraft] = append(crafts[r.MainCraft], r)
+	}
+	var craftNames []string
+	for craft := range crafts {
+		craftNames = append(craftNames, craft)
+	}
+	sort.Strings(craftNames)
+	for _, craft := range craftNames {
+		recipes := crafts[craft]
+		sort.SliceStable(recipes, func(i, j int) bool {
+			return recipes[i].SkillLevels[craft] < recipes[j].SkillLevels[craft]
+		})
+		p.line("\n## %s\n", escapeMarkdown(craft))
+		for _, r := range recipes {
+			p.line("- %d %s", r.SkillLevels[craft], w.recipeLink(p, r.Name))
+		}
+	}
+
+	p.line("\n## Items\n")
+	for _, entry := range w.catalog.Entries() {
+		p.line("- %s", w.itemLink(p, entry.Name))
+	}
+}
+
+func (w *wiki) writeItem(p *page, entry *item.Entry) {
+	p.line("# %s", escapeMarkdown(entry.Name))
+	if len(entry.Aliases) > 0 {
+		p.line("\nAlso spelled: %s", escapeMarkdown(strings.Join(entry.Aliases, ", ")))
+	}
+	if entry.ItemDBID > 0 {
+		p.line("\nItemDB ID: %d", entry.ItemDBID)
+	}
+	if entry.Market != nil {
+		var prices []string
+		if entry.Market.Single != nil {
+			prices = append(prices, fmt.Sprintf("%.0f gil single", *entry.Market.Single))
+		}
+		if entry.Market.Stack != nil {
+			prices = append(prices, fmt.Sprintf("%.0f gil stack", *entry.Market.Stack))
+		}
+		p.line("\nAuction house: %s (%d observations, last %s)", strings.Join(prices, ", "), entry.Market.Observations, entry.Market.Date)
+	}
+
+	var rows [][]string
+	for _, ref := range entry.MadeBy {
+		hq := "NQ"
+		if ref.HighQualityLevel > 0 {
+			hq = fmt.Sprintf("HQ%d", ref.HighQualityLevel)
+		}
+		rows = append(rows, []string{w.recipeLink(p, ref.Recipe), escapeMarkdown(ref.Craft), strconv.Itoa(ref.Level), strconv.Itoa(ref.Count), hq})
+	}
+	p.table("Made by", []string{"Recipe", "Craft", "Level", "Yield", "Quality"}, rows)
+
+	rows = nil
+	for _, ref := range entry.UsedBy {
+		rows = append(rows, []string{w.recipeLink(p, ref.Recipe), escapeMarkdown(ref.Craft), strconv.Itoa(ref.Level), strconv.Itoa(ref.Count)})
+	}
+	p.table("Used in", []string{"Recipe", "Craft", "Level", "Count"}, rows)
+
+	rows = nil
+	for _, ref := range entry.Vendors {
+		merchant := merchants.MerchantInfo{Name: ref.Merchant, Zone: ref.Zone}
+		rows = append(rows, []string{p.link(ref.Merchant, w.path(wikiMerchants, merchantID(merchant))), w.zoneLink(p, ref.Zone),
+			strconv.Itoa(ref.MinPrice), strconv.Itoa(ref.MaxPrice), escapeMarkdown(ref.RankRequirement)})
+	}
+	p.table("Sold by", []string{"Merchant", "Zone", "Min price", "Max price", "Rank"}, rows)
+
+	rows = nil
+	for _, ref := range entry.Mobs {
+		mob := mobdrops.MobInfo{Name: ref.Mob, ZoneName: ref.Zone}
+		kills := ""
+		if ref.AmountDefeated > 0 {
+			kills = strconv.Itoa(ref.AmountDefeated)
+		}
+		rows = append(rows, []string{p.link(ref.Mob, w.path(wikiMobs, mobID(mob))), w.zoneLink(p, ref.Zone),
+			formatLevels(ref.LevelRange), formatPercent(ref.Percent), kills, escapeMarkdown(ref.Source)})
+	}
+	p.table("Dropped by", []string{"Mob", "Zone", "Level", "Drop rate", "Kills", "Source"}, rows)
+
+	rows = nil
+	for _, ref := range entry.GatheringPoints {
+		rows = append(rows, []string{w.zoneLink(p, ref.Zone), escapeMarkdown(ref.PointType), escapeMarkdown(ref.RequiredTool),
+			formatPercent(ref.Percent), escapeMarkdown(strings.ReplaceAll(ref.Tier, "_", " "))})
+	}
+	p.table("Gathered at", []string{"Zone", "Point", "Tool", "Rate", "Tier"}, rows)
+}
+
+func (w *wiki) writeRecipe(p *page, r recipe.CraftingRecipe) {
+	p.line("# %s", escapeMarkdown(r.Name))
+	p.line("\nMakes %s with %s, %s level %d.", w.itemLink(p, r.Result), w.itemLink(p, item.CrystalName(r.Crystal)),
+		escapeMarkdown(r.MainCraft), r.SkillLevels[r.MainCraft])
+	if r.RequiredTools != "" {
+		p.line("\nRequired tools: %s", escapeMarkdown(r.RequiredTools))
+	}
+
+	var crafts []string
+	for craft := range r.SkillLevels {
+		crafts = append(crafts, craft)
+	}
+	sort.Strings(crafts)
+	var rows [][]string
+	for _, craft := range crafts {
+		rows = append(rows, []string{escapeMarkdown(craft), strconv.Itoa(r.SkillLevels[craft])})
+	}
+	p.table("Skills", []string{"Craft", "Level"}, rows)
+
+	rows = nil
+	for _, ingredient := range r.RequiredItems {
+		rows = append(rows, []string{w.itemLink(p, ingredient.Name), strconv.Itoa(ingredient.Count)})
+	}
+	p.table("Ingredients", []string{"Item", "Count"}, rows)
+
+	rows = nil
+	for _, result := range r.AllPossibleResults {
+		quality := "NQ"
+		if result.HighQualityLevel > 0 {
+			quality = fmt.Sprintf("HQ%d", result.HighQualityLevel)
+		}
+		rows = append(rows, []string{quality, w.itemLink(p, result.Name), strconv.Itoa(result.Count)})
+	}
+	p.table("Results", []string{"Quality", "Item", "Count"}, rows)
+}
+
+func (w *wiki) writeMerchant(p *page, merchant merchants.MerchantInfo) {
+	p.line("# %s", escapeMarkdown(merchant.Name))
+	p.line("\nIn %s.", w.zoneLink(p, merchant.Zone))
+
+	var rows [][]string
+	for _, good := range merchant.Items {
+		rows = append(rows, []string{w.itemLink(p, good.Name), strconv.Itoa(good.MinPrice), strconv.Itoa(good.MaxPrice), escapeMarkdown(good.RankRequirement)})
+	}
+	p.table("Goods", []string{"Item", "Min price", "Max price", "Rank"}, rows)
+}
+
+func (w *wiki) writeMob(p *page, mob mobdrops.MobInfo) {
+	p.line("# %s", escapeMarkdown(mob.Name))
+	p.line("\nLevel %s in %s.", formatLevels(mob.LevelRange), w.zoneLink(p, mob.ZoneName))
+
+	var rows [][]string
+	for _, drop := range mob.ItemDrops {
+		counts := ""
+		if drop.AmountDefeated > 0 {
+			counts = fmt.Sprintf("%d / %d", drop.AmountDropped, drop.AmountDefeated)
+		}
+		source := drop.Source
+		if drop.SourceZone != "" {
+			source += " (" + zoneName(drop.SourceZone) + ")"
+		}
+		rows = append(rows, []string{w.itemLink(p, drop.Name), formatPercent(drop.Percent), counts, escapeMarkdown(source)})
+	}
+	p.table("Drops", []string{"Item", "Drop rate", "Dropped / defeated", "Source"}, rows)
+}
+
+func (w *wiki) writeZone(p *page, id string) {
+	p.line("# %s", escapeMarkdown(zoneName(id)))
+	if z, ok := zone.Lookup(id); ok && z.Region != "" {
+		p.line("\nRegion: %s", escapeMarkdown(z.Region))
+	}
+
+	var rows [][]string
+	for _, merchant := range w.merchantsByZone[id] {
+		rows = append(rows, []string{p.link(merchant.Name, w.path(wikiMerchants, merchantID(merchant))), strconv.Itoa(len(merchant.Items))})
+	}
+	p.table("Merchants", []string{"Merchant", "Goods"}, rows)
+
+	rows = nil
+	for _, mob := range w.mobsByZone[id] {
+		rows = append(rows, []string{p.link(mob.Name, w.path(wikiMobs, mobID(mob))), formatLevels(mob.LevelRange), strconv.Itoa(len(mob.ItemDrops))})
+	}
+	p.table("Mobs", []string{"Mob", "Level", "Drops"}, rows)
+
+	rows = nil
+	for _, point := range w.pointsByZone[id] {
+		for _, info := range point.ItemDropInfos {
+			name := info.FriendlyName
+			if name == "" {
+				name = info.Name
+			}
+			rows = append(rows, []string{escapeMarkdown(point.Name), escapeMarkdown(point.RequiredTool), w.itemLink(p, name),
+				formatPercent(point.YieldPercent(info)), escapeMarkdown(strings.ReplaceAll(info.Tier, "_", " "))})
+		}
+	}
+	p.table("Gathering", []string{"Point", "Tool", "Item", "Rate", "Tier"}, rows)
+}
@@ -0,0 +1,57 @@
+package export
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteWiki(t *testing.T) {
+	dir := t.TempDir()
+	if err := WriteWiki(dir, testDataset()); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		page     string
+		expected string
+	}{
+		{"index.md", "- 7 [Woodworking-7-Ash Lumber-From-1-Ash Log](recipes/Woodworking-7-Ash_Lumber-From-1-Ash_Log.md)"},
+		{"recipes/Woodworking-7-Ash_Lumber-From-1-Ash_Log.md", "| [Ash Log](../items/Ash_Log.md) | 1 |"},
+		{"recipes/Woodworking-7-Ash_Lumber-From-1-Ash_Log.md", "| HQ1 | [Ash Lumber](../items/Ash_Lumber.md) | 2 |"},
+		{"items/Ash_Log.md", "| [Bogy](../mobs/Bogy_Valkurm_Dunes.md) | [Valkurm Dunes](../zones/Valkurm_Dunes.md) | 18-21 | unknown |  | unknown |"},
+		{"items/San_d_Orian_Grape.md", "| [Ostalie](../merchants/Ostalie_Southern_San_dOria.md) | [Southern San d'Oria](../zones/Southern_San_dOria.md) | 60 | 70 |  |"},
+		{"mobs/Bogy_Valkurm_Dunes.md", "| [Bloody Robe](../items/Bloody_Robe.md) | 39.2% | 652 / 1665 | scrape |"},
+		{"zones/Valkurm_Dunes.md", "| [Bogy](../mobs/Bogy_Valkurm_Dunes.md) | 18-21 | 2 |"},
+	}
+	for _, tc := range testCases {
+		fileContent, err := os.ReadFile(filepath.Join(dir, tc.page))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(fileContent), tc.expected) {
+			t.Errorf("Expected %s in %s, but got\n%s", tc.expected, tc.page, fileContent)
+		}
+	}
+}
+
+func TestWikiPath(t *testing.T) {
+	w := newWiki(testDataset())
+	testCases := []struct {
+		id       string
+		expected string
+	}{
+		{"Ash Lumber", "items/Ash_Lumber.md"},
+		{"Ash_Lumber", "items/Ash_Lumber_2.md"},
+		{" ", "items/page.md"},
+		{"ギサールの野菜", "items/page_2.md"},
+		{"..", "items/page_3.md"},
+		{"Ash Lumber", "items/Ash_Lumber.md"},
+	}
+	for _, tc := range testCases {
+		if result := w.path("items", tc.id); result != filepath.FromSlash(tc.expected) {
+			t.Errorf("Expected %q to be at %s, but got %s", tc.id, tc.expected, result)
+		}
+	}
+}
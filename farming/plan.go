@@ -0,0 +1,222 @@
+// Package farming ranks the mobs that drop an item and estimates the kills
+// needed to collect it.
+package farming
+
+import (
+	"encoding/json"
+	"ffxi/mobdrops"
+	"math"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Options controls which mobs are considered and what target is planned for.
+type Options struct {
+	ItemName string
+	Level    int
+	// MaxLevelsAbove skips mobs whose minimum level is more than this many
+	// levels above the player. Zero means DefaultMaxLevelsAbove.
+	MaxLevelsAbove int
+	// MaxLevelsBelow skips mobs whose maximum level is more than this many
+	// levels below the player. Zero means no lower limit.
+	MaxLevelsBelow int
+	// Count is how many items to collect, Probability how sure to be of it,
+	// between 0 and 1 exclusive, e.g. 0.9 rather than 90.
+	Count       int
+	Probability float64
+	// AssumedSampleSize is used for confidence when a drop has no kill count.
+	AssumedSampleSize int
+}
+
+// Defaults applied to zero-valued Options fields.
+const (
+	DefaultMaxLevelsAbove    = 5
+	DefaultProbability       = 0.9
+	DefaultAssumedSampleSize = 10
+)
+
+// z value for the 95% Wilson score interval used to weight drop rates.
+const confidenceZ = 1.96
+
+// Candidate is one mob ranked as a source of the item.
+type Candidate struct {
+	MobName    string               `json:"MobName"`
+	ZoneName   string               `json:"ZoneName"`
+	LevelRange *mobdrops.LevelRange `json:"LevelRange"`
+	Percent    float64              `json:"Percent"`
+	Source     string               `json:"Source"`
+	SampleSize int                  `json:"SampleSize"`
+	// LowerBoundPercent is the pessimistic drop rate given the sample size.
+	LowerBoundPercent    float64 `json:"LowerBoundPercent"`
+	ExpectedKillsPerDrop float64 `json:"ExpectedKillsPerDrop"`
+	WeightedKillsPerDrop float64 `json:"WeightedKillsPerDrop"`
+	// KillsForTarget is the number of kills needed to collect Options.Count
+	// items with Options.Probability, -1 when the probability is out of range.
+	KillsForTarget int `json:"KillsForTarget"`
+}
+
+// LoadMobs reads mobdrops output files and returns all their mobs.
+func LoadMobs(paths ...string) ([]mobdrops.MobInfo, error) {
+	var mobs []mobdrops.MobInfo
+	for _, path := range paths {
+		fileContent, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		var fileMobs []mobdrops.MobInfo
+		err = json.Unmarshal(fileContent, &fileMobs)
+		if err != nil {
+			return nil, err
+		}
+		mobs = append(mobs, fileMobs...)
+	}
+
+	return mobs, nil
+}
+
+// Plan ranks every mob that drops the item by confidence weighted kills per
+// drop, best first. Drops with an unknown rate are left out.
+func Plan(mobs []mobdrops.MobInfo, opts Options) []Candidate {
+	opts = withDefaults(opts)
+
+	var candidates []Candidate
+	for _, mob := range mobs {
+		if !levelInRange(mob.LevelRange, opts) {
+			continue
+		}
+		for _, drop := range mob.ItemDrops {
+			if !sameItemName(drop.Name, opts.ItemName) || drop.Percent == nil || *drop.Percent <= 0 {
+				continue
+			}
+
+			rate := *drop.Percent / 100
+			sampleSize := drop.AmountDefeated
+			if sampleSize <= 0 {
+				sampleSize = opts.AssumedSampleSize
+			}
+			lowerBound := wilsonLowerBound(rate, sampleSize)
+
+			candidates = append(candidates, Candidate{
+				MobName:              mob.Name,
+				ZoneName:             mob.ZoneName,
+				LevelRange:           mob.LevelRange,
+				Percent:              *drop.Percent,
+				Source:               drop.Source,
+				SampleSize:           drop.AmountDefeated,
+				LowerBoundPercent:    lowerBound * 100,
+				ExpectedKillsPerDrop: 1 / rate,
+				WeightedKillsPerDrop: 1 / lowerBound,
+				KillsForTarget:       KillsForProbability(opts.Count, rate, opts.Probability),
+			})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].WeightedKillsPerDrop < candidates[j].WeightedKillsPerDrop
+	})
+	return candidates
+}
+
+// KillsForProbability returns the fewest kills needed to collect n drops with
+// at least the given probability when each kill drops with rate p. The
+// number of kills follows a negative binomial distribution. It returns -1
+// when p is not positive or the probability is outside (0, 1), as no number
+// of kills is certain.
+func KillsForProbability(n int, p, probability float64) int {
+	if probability <= 0 || probability >= 1 || math.IsNaN(probability) {
+		return -1
+	}
+	if n <= 0 {
+		return 0
+	}
+	if p <= 0 {
+		return -1
+	}
+	if p >= 1 {
+		return n
+	}
+
+	// Grow an upper bound, then binary search the smallest kill count
+	high := n
+	for collectProbability(high, n, p) < probability {
+		high *= 2
+	}
+	low := n
+	for low < high {
+		mid := (low + high) / 2
+		if collectProbability(mid, n, p) >= probability {
+			high = mid
+		} else {
+			low = mid + 1
+		}
+	}
+	return low
+}
+
+// collectProbability is the chance of at least n drops in k kills.
+func collectProbability(k, n int, p float64) float64 {
+	// 1 - P(Binomial(k, p) < n), summed in log space to avoid overflow
+	var below float64
+	for i := 0; i < n && i <= k; i++ {
+		below += math.Exp(logChoose(k, i) + float64(i)*math.Log(p) + float64(k-i)*math.Log1p(-p))
+	}
+	return 1 - below
+}
+
+func logChoose(n, k int) float64 {
+	a, _ := math.Lgamma(float64(n + 1))
+	b, _ := math.Lgamma(float64(k + 1))
+	c, _ := math.Lgamma(float64(n - k + 1))
+	return a - b - c
+}
+
+// wilsonLowerBound returns the lower bound of the Wilson score interval.
+func wilsonLowerBound(p float64, n int) float64 {
+	size := float64(n)
+	z2 := confidenceZ * confidenceZ
+	center := p + z2/(2*size)
+	spread := confidenceZ * math.Sqrt(p*(1-p)/size+z2/(4*size*size))
+	lowerBound := (center - spread) / (1 + z2/size)
+	if lowerBound <= 0 {
+		// Keep the ranking finite for tiny samples
+		return p / (1 + z2/size)
+	}
+	return lowerBound
+}
+
+func levelInRange(levelRange *mobdrops.LevelRange, opts Options) bool {
+	if levelRange == nil || opts.Level <= 0 {
+		// Unknown levels are kept so they can still be considered
+		return true
+	}
+	if levelRange.Min > opts.Level+opts.MaxLevelsAbove {
+		return false
+	}
+	if opts.MaxLevelsBelow > 0 && levelRange.Max < opts.Level-opts.MaxLevelsBelow {
+		return false
+	}
+	return true
+}
+
+// sameItemName compares item names ignoring case and underscores.
+func sameItemName(a, b string) bool {
+	return strings.EqualFold(strings.ReplaceAll(a, "_", " "), strings.ReplaceAll(b, "_", " "))
+}
+
+func withDefaults(opts Options) Options {
+	if opts.MaxLevelsAbove == 0 {
+		opts.MaxLevelsAbove = DefaultMaxLevelsAbove
+	}
+	if opts.Count == 0 {
+		opts.Count = 1
+	}
+	if opts.Probability == 0 {
+		opts.Probability = DefaultProbability
+	}
+	if opts.AssumedSampleSize == 0 {
+		opts.AssumedSampleSize = DefaultAssumedSampleSize
+	}
+	return opts
+}
@@ -0,0 +1,66 @@
+package farming
+
+import (
+	"ffxi/mobdrops"
+	"fmt"
+	"testing"
+)
+
+func TestKillsForProbability(t *testing.T) {
+	testCases := []struct {
+		n           int
+		p           float64
+		probability float64
+		expected    int
+	}{
+		{1, 0.5, 0.5, 1},
+		{1, 0.5, 0.75, 2},
+		{1, 0.5, 0.9, 4},
+		{1, 0.1, 0.9, 22},
+		{2, 0.5, 0.5, 3},
+		{3, 1, 0.99, 3},
+		{0, 0.2, 0.9, 0},
+		{1, 0, 0.9, -1},
+		// Probabilities are fractions, not percents
+		{1, 0.5, 90, -1},
+		{1, 0.5, 1, -1},
+		{1, 0.5, 0, -1},
+		{1, 0.5, -0.5, -1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("n=%d p=%v q=%v", tc.n, tc.p, tc.probability), func(t *testing.T) {
+			result := KillsForProbability(tc.n, tc.p, tc.probability)
+			if result != tc.expected {
+				t.Errorf("Expected %d kills, but got %d", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestPlan(t *testing.T) {
+	percent := func(f float64) *float64 { return &f }
+	mobs := []mobdrops.MobInfo{
+		{Name: "Bogy", ZoneName: "Valkurm_Dunes", LevelRange: &mobdrops.LevelRange{Min: 18, Max: 21},
+			ItemDrops: []mobdrops.ItemDrop{{Name: "Bloody Robe", Percent: percent(39.2), AmountDefeated: 1665}}},
+		{Name: "Bogy", ZoneName: "Jugner_Forest", LevelRange: &mobdrops.LevelRange{Min: 18, Max: 21},
+			ItemDrops: []mobdrops.ItemDrop{{Name: "Bloody Robe", Percent: percent(50), AmountDefeated: 4}}},
+		{Name: "Bogy", ZoneName: "Pashhow_Marshlands", LevelRange: &mobdrops.LevelRange{Min: 40, Max: 45},
+			ItemDrops: []mobdrops.ItemDrop{{Name: "Bloody Robe", Percent: percent(60), AmountDefeated: 500}}},
+		{Name: "Ghoul", ZoneName: "Valkurm_Dunes",
+			ItemDrops: []mobdrops.ItemDrop{{Name: "bloody_robe"}}},
+	}
+
+	candidates := Plan(mobs, Options{ItemName: "Bloody Robe", Level: 20, Count: 2})
+
+	if len(candidates) != 2 {
+		t.Fatalf("Expected 2 candidates, but got %d: %+v", len(candidates), candidates)
+	}
+	// The big sample outranks the higher but barely observed rate
+	if candidates[0].ZoneName != "Valkurm_Dunes" || candidates[1].ZoneName != "Jugner_Forest" {
+		t.Errorf("Expected Valkurm_Dunes before Jugner_Forest, but got %s, %s", candidates[0].ZoneName, candidates[1].ZoneName)
+	}
+	if candidates[0].KillsForTarget != KillsForProbability(2, 0.392, DefaultProbability) {
+		t.Errorf("Unexpected kills for target %d", candidates[0].KillsForTarget)
+	}
+}
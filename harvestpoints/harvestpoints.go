@@ -0,0 +1,420 @@
+// Package harvestpoints turns gathering point scrapes into harvest points per
+// zone and point type, and validates their rates against the tier bands.
+package harvestpoints
+
+import (
+	"encoding/json"
+	"ffxi/transform"
+	"ffxi/zone"
+	"fmt"
+	"log"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Harvest rates are written as drops out of this many synthetic attempts.
+const totalKnownDefeated = 100
+
+// View selects how harvest rates are read and written.
+type View string
+
+const (
+	// ExactView carries the source percent and tier next to the synthetic counts.
+	ExactView View = "exact"
+	// CountView only carries the rounded TotalKnownDrops out of TotalKnownDefeated.
+	CountView View = "counts"
+)
+
+// PointType is a kind of gathering point.
+type PointType string
+
+const (
+	Harvesting PointType = "harvesting"
+	Logging    PointType = "logging"
+	Mining     PointType = "mining"
+	Excavation PointType = "excavation"
+	Clamming   PointType = "clamming"
+	Fishing    PointType = "fishing"
+)
+
+// pointTypes lists every point type in output order with its point name and
+// the tool needed to use it.
+var pointTypes = []struct {
+	Type PointType
+	Name string
+	Tool string
+}{
+	{Harvesting, "Harvesting Point", "Sickle"},
+	{Logging, "Logging Point", "Hatchet"},
+	{Mining, "Mining Point", "Pickaxe"},
+	{Excavation, "Excavation Point", "Pickaxe"},
+	{Clamming, "Clamming Point", "Clamming Kit"},
+	{Fishing, "Fishing Spot", "Fishing Rod"},
+}
+
+type HarvestItem struct {
+	Item      string `json:"Item"`
+	Abundance string `json:"Abundance"`
+}
+
+// Abundance is a parsed abundance string like "Common(15.1%)". Percent is nil
+// when the source has no rate, e.g. "Unknown(0%) (Rare)".
+type Abundance struct {
+	Tier    string
+	Percent *float64
+}
+
+// HarvestPoint has the MobInfo shape so harvest yields can be read like drops.
+type HarvestPoint struct {
+	SchemaVersion      int            `json:"SchemaVersion"`
+	Name               string         `json:"Name"`
+	PointType          PointType      `json:"PointType"`
+	RequiredTool       string         `json:"RequiredTool"`
+	LevelRange         *LevelRange    `json:"LevelRange"`
+	ZoneName           string         `json:"ZoneName"`
+	ItemDrops          []ItemDrop     `json:"ItemDrops"`
+	ItemDropInfos      []ItemDropInfo `json:"ItemDropInfos"`
+	TotalKnownDefeated int            `json:"TotalKnownDefeated"`
+}
+
+type LevelRange struct {
+	Min int `json:"Min"`
+	Max int `json:"Max"`
+}
+
+// ItemDrop is kept so harvest points share the MobInfo shape, it is always empty.
+type ItemDrop struct {
+	Name    string   `json:"Name"`
+	Percent *float64 `json:"Percent"`
+}
+
+// ItemDropInfo is one harvest yield. TotalKnownDrops is the rate rounded to a
+// whole count, Percent and Tier keep the source values without rounding.
+// Provenance is the input row of the yield.
+type ItemDropInfo struct {
+	Name            string                `json:"Name"`
+	FriendlyName    string                `json:"FriendlyName"`
+	TotalKnownDrops int                   `json:"TotalKnownDrops"`
+	Percent         *float64              `json:"Percent,omitempty"`
+	Tier            string                `json:"Tier,omitempty"`
+	Provenance      *transform.Provenance `json:"Provenance,omitempty"`
+}
+
+// Rate returns the yield rate of info as a fraction in the given view. It
+// returns false when the rate is unknown, which count view files can only
+// express as a missing TotalKnownDefeated.
+func (p HarvestPoint) Rate(info ItemDropInfo, view View) (float64, bool) {
+	if view == CountView {
+		if p.TotalKnownDefeated == 0 || info.Percent == nil && info.Tier != "" {
+			return 0, false
+		}
+		return float64(info.TotalKnownDrops) / float64(p.TotalKnownDefeated), true
+	}
+	if info.Percent == nil {
+		return 0, false
+	}
+	return *info.Percent / 100, true
+}
+
+// YieldPercent returns the yield rate of info in percent in whichever view it
+// was written, reading the synthetic counts when info has no Percent. It
+// returns nil when the rate is unknown.
+func (p HarvestPoint) YieldPercent(info ItemDropInfo) *float64 {
+	if info.Percent != nil {
+		return info.Percent
+	}
+	rate, ok := p.Rate(info, CountView)
+	if !ok {
+		return nil
+	}
+	percent := rate * 100
+	return &percent
+}
+
+// Config holds the harvest specific settings of Run and Validate.
+type Config struct {
+	// View is the rate view to write, ExactView or CountView.
+	View View
+	// PointType is the point type of zones listing items directly.
+	PointType PointType
+	// BandsFile is a JSON file of tier -> {Min, Max} percent bands used by
+	// Validate instead of the default bands.
+	BandsFile string
+}
+
+// DefaultConfig writes exact rates and treats bare item lists as harvesting points.
+var DefaultConfig = Config{View: ExactView, PointType: Harvesting}
+
+// Validate checks the view and point type are ones Run accepts.
+func (c Config) Validate() error {
+	if c.View != ExactView && c.View != CountView {
+		return fmt.Errorf("unknown view %q, expected %s or %s", c.View, ExactView, CountView)
+	}
+	if !knownPointType(c.PointType) {
+		var known []string
+		for _, pointType := range pointTypes {
+			known = append(known, string(pointType.Type))
+		}
+		return fmt.Errorf("unknown point type %q, expected one of %s", c.PointType, strings.Join(known, ", "))
+	}
+	return nil
+}
+
+// Run reads the harvest inputs, input.json by default, from the input
+// directory and writes one file of gathering points per zone into the output
+// directory.
+func Run(opts transform.Options, cfg Config) error {
+	if err := opts.CheckFormat(transform.Formats...); err != nil {
+		return err
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	inputFiles, err := opts.InputFiles("input.json")
+	if err != nil {
+		return err
+	}
+
+	now := transform.Now()
+	harvestPoints := make(map[string][]HarvestPoint)
+	for _, inputFile := range inputFiles {
+		inputJSON, err := opts.ReadInput(inputFile)
+		if err != nil {
+			return err
+		}
+
+		filePoints, err := transformHarvestPoints(inputJSON, cfg.PointType, cfg.View)
+		if err != nil {
+			return fmt.Errorf("%s: %v", inputFile, err)
+		}
+		for zone, points := range filePoints {
+			for _, point := range points {
+				for _, info := range point.ItemDropInfos {
+					info.Provenance.Stamp(inputFile, now)
+				}
+			}
+			harvestPoints[zone] = append(harvestPoints[zone], points...)
+		}
+	}
+	opts.Logf("Transformed harvest points of %d zones", len(harvestPoints))
+
+	return writeHarvestPointFiles(opts, harvestPoints)
+}
+
+// Validate checks the harvest inputs against the tier bands without writing
+// anything.
+func Validate(opts transform.Options, cfg Config) (ValidationReport, error) {
+	inputFiles, err := opts.InputFiles("input.json")
+	if err != nil {
+		return ValidationReport{}, err
+	}
+
+	bands := defaultBands
+	if cfg.BandsFile != "" {
+		bands, err = loadBands(cfg.BandsFile)
+		if err != nil {
+			return ValidationReport{}, err
+		}
+	}
+
+	// A clean run still reports an empty list rather than null
+	report := ValidationReport{Issues: []ValidationIssue{}}
+	for _, inputFile := range inputFiles {
+		inputJSON, err := opts.ReadInput(inputFile)
+		if err != nil {
+			return ValidationReport{}, err
+		}
+
+		fileReport, err := validateHarvestPoints(inputJSON, cfg.PointType, bands)
+		if err != nil {
+			return ValidationReport{}, fmt.Errorf("%s: %v", inputFile, err)
+		}
+		report.Zones += fileReport.Zones
+		report.Items += fileReport.Items
+		report.Issues = append(report.Issues, fileReport.Issues...)
+	}
+
+	return report, nil
+}
+
+// transformHarvestPoints turns zone input into one gathering point per zone and
+// point type, keyed by canonical zone ID. A zone maps either to a list of items, which are points of
+// defaultType, or to an object of point type to items:
+//
+//	{"Giddeus": [...], "Zeruhn_Mines": {"mining": [...], "logging": [...]}}
+//
+// The count view leaves out the exact rates and items without a rate,
+// matching the hand-made files.
+func transformHarvestPoints(inputJSON []byte, defaultType PointType, view View) (map[string][]HarvestPoint, error) {
+	zones, err := parseZones(inputJSON, defaultType)
+	if err != nil {
+		return nil, err
+	}
+
+	harvestPoints := make(map[string][]HarvestPoint)
+	for zoneName, itemsByType := range zones {
+		zoneID := zone.ID(zoneName)
+		for _, pointType := range pointTypes {
+			items, ok := itemsByType[pointType.Type]
+			if !ok {
+				continue
+			}
+
+			infos, err := transformItems(zoneName, items, view)
+			if err != nil {
+				return nil, err
+			}
+
+			harvestPoints[zoneID] = append(harvestPoints[zoneID], HarvestPoint{
+				SchemaVersion:      transform.SchemaVersion,
+				Name:               pointType.Name,
+				PointType:          pointType.Type,
+				RequiredTool:       pointType.Tool,
+				ZoneName:           zoneID,
+				ItemDrops:          []ItemDrop{},
+				ItemDropInfos:      infos,
+				TotalKnownDefeated: totalKnownDefeated,
+			})
+		}
+	}
+
+	return harvestPoints, nil
+}
+
+// parseZones reads the zone input into zone -> point type -> items.
+func parseZones(inputJSON []byte, defaultType PointType) (map[string]map[PointType][]HarvestItem, error) {
+	if !knownPointType(defaultType) {
+		return nil, fmt.Errorf("unknown point type: %s", defaultType)
+	}
+
+	var rawZones map[string]json.RawMessage
+	if err := json.Unmarshal(inputJSON, &rawZones); err != nil {
+		return nil, err
+	}
+
+	zones := make(map[string]map[PointType][]HarvestItem)
+	for zone, rawZone := range rawZones {
+		var items []HarvestItem
+		if err := json.Unmarshal(rawZone, &items); err == nil {
+			zones[zone] = map[PointType][]HarvestItem{defaultType: items}
+			continue
+		}
+
+		var itemsByType map[PointType][]HarvestItem
+		if err := json.Unmarshal(rawZone, &itemsByType); err != nil {
+			return nil, fmt.Errorf("zone %s: %v", zone, err)
+		}
+		for pointType := range itemsByType {
+			if !knownPointType(pointType) {
+				return nil, fmt.Errorf("zone %s: unknown point type: %s", zone, pointType)
+			}
+		}
+		zones[zone] = itemsByType
+	}
+
+	return zones, nil
+}
+
+func knownPointType(pointType PointType) bool {
+	for _, known := range pointTypes {
+		if known.Type == pointType {
+			return true
+		}
+	}
+	return false
+}
+
+// transformItems turns the items of one point into ItemDropInfos.
+func transformItems(zone string, items []HarvestItem, view View) ([]ItemDropInfo, error) {
+	var infos []ItemDropInfo
+	for row, item := range items {
+		abundance, err := parseAbundance(item.Abundance)
+		if err != nil {
+			return nil, fmt.Errorf("zone %s item %s: %v", zone, item.Item, err)
+		}
+		if abundance.Percent == nil && view == CountView {
+			log.Printf("Skipping %s in %s, no known rate in %q", item.Item, zone, item.Abundance)
+			continue
+		}
+
+		info := ItemDropInfo{
+			Name:         strings.ReplaceAll(item.Item, "_", " "),
+			FriendlyName: item.Item,
+			Provenance:   &transform.Provenance{Row: row},
+		}
+		if abundance.Percent != nil {
+			info.TotalKnownDrops = int(math.Round(*abundance.Percent * totalKnownDefeated / 100))
+		}
+		if view == ExactView {
+			info.Percent = abundance.Percent
+			info.Tier = abundance.Tier
+		}
+		infos = append(infos, info)
+	}
+
+	return infos, nil
+}
+
+var abundanceRe = regexp.MustCompile(`^(\w+)\((\d+(?:\.\d+)?)%\)(?:\s*\((\w+)\))?$`)
+
+// parseAbundance parses strings like "Very_Rare(4.6%)" into tier and percent.
+// "Unknown(0%) (Rare)" yields the Rare tier with no percent, and text without
+// a rate like "Received_with_quest_active" is kept as the tier.
+func parseAbundance(abundance string) (Abundance, error) {
+	abundance = strings.TrimSpace(abundance)
+	match := abundanceRe.FindStringSubmatch(abundance)
+	if match == nil {
+		if regexp.MustCompile(`^\w+$`).MatchString(abundance) {
+			return Abundance{Tier: abundance}, nil
+		}
+		return Abundance{}, fmt.Errorf("unable to parse abundance: %s", abundance)
+	}
+
+	if match[1] == "Unknown" {
+		return Abundance{Tier: match[3]}, nil
+	}
+
+	percent, err := strconv.ParseFloat(match[2], 64)
+	if err != nil {
+		return Abundance{}, err
+	}
+	return Abundance{Tier: match[1], Percent: &percent}, nil
+}
+
+// YieldTable flattens gathering points into one row per yield.
+func YieldTable(points []HarvestPoint) transform.Table {
+	table := transform.Table{Columns: []string{"Zone", "Point", "PointType", "RequiredTool", "Item", "TotalKnownDrops", "Percent", "Tier"}}
+	for _, point := range points {
+		for _, info := range point.ItemDropInfos {
+			name := info.FriendlyName
+			if name == "" {
+				name = info.Name
+			}
+			table.Rows = append(table.Rows, []string{
+				point.ZoneName,
+				point.Name,
+				string(point.PointType),
+				point.RequiredTool,
+				name,
+				strconv.Itoa(info.TotalKnownDrops),
+				transform.FormatFloat(info.Percent),
+				info.Tier,
+			})
+		}
+	}
+	return table
+}
+
+func writeHarvestPointFiles(opts transform.Options, harvestPoints map[string][]HarvestPoint) error {
+	for zone, points := range harvestPoints {
+		err := opts.Write(zone, points, func() transform.Table { return YieldTable(points) })
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,248 @@
+package harvestpoints
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestParseAbundance(t *testing.T) {
+	percent := func(f float64) *float64 { return &f }
+	testCases := []struct {
+		abundance string
+		expected  Abundance
+	}{
+		{"Common(15.1%)", Abundance{Tier: "Common", Percent: percent(15.1)}},
+		{"Very_Rare(4.6%)", Abundance{Tier: "Very_Rare", Percent: percent(4.6)}},
+		{"Uncommon(11%)", Abundance{Tier: "Uncommon", Percent: percent(11)}},
+		{"Unknown(0%) (Very_Rare)", Abundance{Tier: "Very_Rare"}},
+		{"Received_with_quest_active", Abundance{Tier: "Received_with_quest_active"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.abundance, func(t *testing.T) {
+			result, err := parseAbundance(tc.abundance)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(result, tc.expected) {
+				t.Errorf("Expected %+v, but got %+v", tc.expected, result)
+			}
+		})
+	}
+
+	if _, err := parseAbundance("Common(lots)"); err == nil {
+		t.Error("Expected an error for an unparsable abundance")
+	}
+}
+
+// TestTransformMatchesHandMadeFiles checks the count view against the hand-made
+// files in allHarvestPoints. Those files only list some of each zone's items,
+// so only the items they list are compared. Giddeus spells one Name with
+// underscores, so Names are compared with underscores as spaces. The files
+// predate point types, schema versions and provenance, so those are left out
+// of the comparison.
+func TestTransformMatchesHandMadeFiles(t *testing.T) {
+	inputJSON, err := ioutil.ReadFile("input.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	harvestPoints, err := transformHarvestPoints(inputJSON, Harvesting, CountView)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zones, err := parseZones(inputJSON, Harvesting)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, zone := range []string{"Giddeus", "Bhaflau_Thickets"} {
+		t.Run(zone, func(t *testing.T) {
+			fileContent, err := ioutil.ReadFile(filepath.Join("allHarvestPoints", zone+".json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var expected []HarvestPoint
+			if err := json.Unmarshal(fileContent, &expected); err != nil {
+				t.Fatal(err)
+			}
+			for i := range expected {
+				for j := range expected[i].ItemDropInfos {
+					expected[i].ItemDropInfos[j].Name = strings.ReplaceAll(expected[i].ItemDropInfos[j].Name, "_", " ")
+				}
+			}
+
+			generated := onlyListedItems(harvestPoints[zone], expected)
+			// The hand-made files list a sample of each zone, so the filter
+			// must keep every listed item and drop only other rated inputs
+			if len(generated) != len(expected) || len(generated) != 1 {
+				t.Fatalf("Expected 1 point, but got %d", len(harvestPoints[zone]))
+			}
+			listed, filtered, all := len(expected[0].ItemDropInfos), len(generated[0].ItemDropInfos), len(harvestPoints[zone][0].ItemDropInfos)
+			if filtered != listed || all != ratedItems(t, zones[zone][Harvesting]) {
+				t.Errorf("Expected %d listed of %d rated items, but got %d of %d generated", listed, ratedItems(t, zones[zone][Harvesting]), filtered, all)
+			}
+			if !reflect.DeepEqual(generated, expected) {
+				t.Errorf("Expected %+v, but got %+v", expected, generated)
+			}
+		})
+	}
+}
+
+// ratedItems counts the distinct input items with a rate, which the count
+// view writes.
+func ratedItems(t *testing.T, items []HarvestItem) int {
+	rated := make(map[string]bool)
+	for _, harvestItem := range items {
+		abundance, err := parseAbundance(harvestItem.Abundance)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if abundance.Percent != nil {
+			rated[strings.ReplaceAll(harvestItem.Item, " ", "_")] = true
+		}
+	}
+	return len(rated)
+}
+
+// onlyListedItems drops generated items that the expected points don't list.
+func onlyListedItems(generated, expected []HarvestPoint) []HarvestPoint {
+	listed := make(map[string]bool)
+	for _, point := range expected {
+		for _, info := range point.ItemDropInfos {
+			listed[info.FriendlyName] = true
+		}
+	}
+
+	var filtered []HarvestPoint
+	for _, point := range generated {
+		var infos []ItemDropInfo
+		for _, info := range point.ItemDropInfos {
+			if listed[info.FriendlyName] {
+				info.Provenance = nil
+				infos = append(infos, info)
+			}
+		}
+		point.ItemDropInfos = infos
+		point.SchemaVersion = 0
+		point.PointType = ""
+		point.RequiredTool = ""
+		filtered = append(filtered, point)
+	}
+	return filtered
+}
+
+func TestExactView(t *testing.T) {
+	inputJSON := []byte(`{"Bhaflau_Thickets": [
+		{"Item": "Mohbwa_Grass", "Abundance": "Common(15.1%)"},
+		{"Item": "Eastern_Ginger", "Abundance": "Very_Rare(3.6%)"},
+		{"Item": "Mistroot", "Abundance": "Received_with_quest_active"}
+	]}`)
+
+	harvestPoints, err := transformHarvestPoints(inputJSON, Harvesting, ExactView)
+	if err != nil {
+		t.Fatal(err)
+	}
+	point := harvestPoints["Bhaflau_Thickets"][0]
+
+	testCases := []struct {
+		exact    float64
+		counts   float64
+		known    bool
+		tier     string
+		expected int
+	}{
+		{0.151, 0.15, true, "Common", 15},
+		{0.036, 0.04, true, "Very_Rare", 4},
+		{0, 0, false, "Received_with_quest_active", 0},
+	}
+
+	if len(point.ItemDropInfos) != len(testCases) {
+		t.Fatalf("Expected %d items, but got %d", len(testCases), len(point.ItemDropInfos))
+	}
+	for i, tc := range testCases {
+		info := point.ItemDropInfos[i]
+		t.Run(info.FriendlyName, func(t *testing.T) {
+			exact, known := point.Rate(info, ExactView)
+			if known != tc.known || math.Abs(exact-tc.exact) > 1e-9 {
+				t.Errorf("Expected exact rate %v (%v), but got %v (%v)", tc.exact, tc.known, exact, known)
+			}
+			counts, known := point.Rate(info, CountView)
+			if known != tc.known || math.Abs(counts-tc.counts) > 1e-9 {
+				t.Errorf("Expected count rate %v (%v), but got %v (%v)", tc.counts, tc.known, counts, known)
+			}
+			if info.Tier != tc.tier || info.TotalKnownDrops != tc.expected {
+				t.Errorf("Expected %s with %d drops, but got %s with %d", tc.tier, tc.expected, info.Tier, info.TotalKnownDrops)
+			}
+		})
+	}
+}
+
+func TestYieldPercent(t *testing.T) {
+	percent := 15.1
+	point := HarvestPoint{TotalKnownDefeated: 100}
+	testCases := []struct {
+		name     string
+		info     ItemDropInfo
+		expected string
+	}{
+		{"exact", ItemDropInfo{Percent: &percent, Tier: "Common", TotalKnownDrops: 15}, "15.1"},
+		{"counts", ItemDropInfo{TotalKnownDrops: 4}, "4"},
+		{"unknown", ItemDropInfo{Tier: "Received_with_quest_active"}, "unknown"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := "unknown"
+			if yield := point.YieldPercent(tc.info); yield != nil {
+				result = strconv.FormatFloat(*yield, 'f', -1, 64)
+			}
+			if result != tc.expected {
+				t.Errorf("Expected %s, but got %s", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestPointTypes(t *testing.T) {
+	inputJSON := []byte(`{
+		"Giddeus": [{"Item": "Moko_Grass", "Abundance": "Uncommon(10.3%)"}],
+		"Zeruhn_Mines": {
+			"mining": [{"Item": "Copper_Ore", "Abundance": "Common(30%)"}],
+			"harvesting": [{"Item": "Moko_Grass", "Abundance": "Common(20%)"}]
+		}
+	}`)
+
+	harvestPoints, err := transformHarvestPoints(inputJSON, Logging, ExactView)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		zone     string
+		expected [][3]string
+	}{
+		{"Giddeus", [][3]string{{"Logging Point", "logging", "Hatchet"}}},
+		{"Zeruhn_Mines", [][3]string{{"Harvesting Point", "harvesting", "Sickle"}, {"Mining Point", "mining", "Pickaxe"}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.zone, func(t *testing.T) {
+			var result [][3]string
+			for _, point := range harvestPoints[tc.zone] {
+				result = append(result, [3]string{point.Name, string(point.PointType), point.RequiredTool})
+			}
+			if !reflect.DeepEqual(result, tc.expected) {
+				t.Errorf("Expected %v, but got %v", tc.expected, result)
+			}
+		})
+	}
+
+	if _, err := transformHarvestPoints([]byte(`{"Giddeus": {"chopping": []}}`), Harvesting, ExactView); err == nil {
+		t.Error("Expected an error for an unknown point type")
+	}
+}
@@ -0,0 +1,53 @@
+package harvestpoints
+
+import (
+	"encoding/json"
+	"ffxi/transform"
+	"ffxi/zone"
+	"fmt"
+	"strings"
+)
+
+// Upgrade decodes a gathering point written by any version of the harvest
+// command, or the hand-made allHarvestPoints files, and brings it up to
+// transform.SchemaVersion. The point type and tool are derived from the point
+// Name. The returned notes name what could not be derived.
+func Upgrade(data []byte) (HarvestPoint, []string, error) {
+	var point HarvestPoint
+	if err := json.Unmarshal(data, &point); err != nil {
+		return point, nil, err
+	}
+	if point.SchemaVersion >= transform.SchemaVersion {
+		return point, nil, nil
+	}
+
+	var notes []string
+	point.ZoneName = zone.ID(point.ZoneName)
+	if point.ItemDrops == nil {
+		point.ItemDrops = []ItemDrop{}
+	}
+
+	if point.PointType == "" {
+		point.PointType = Harvesting
+		found := false
+		for _, pointType := range pointTypes {
+			if strings.EqualFold(point.Name, pointType.Name) {
+				point.PointType = pointType.Type
+				found = true
+			}
+		}
+		if !found {
+			notes = append(notes, fmt.Sprintf("PointType: unknown point name %q, assumed %s", point.Name, Harvesting))
+		}
+	}
+	if point.RequiredTool == "" {
+		for _, pointType := range pointTypes {
+			if pointType.Type == point.PointType {
+				point.RequiredTool = pointType.Tool
+			}
+		}
+	}
+
+	point.SchemaVersion = transform.SchemaVersion
+	return point, notes, nil
+}
@@ -0,0 +1,159 @@
+package harvestpoints
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Checks reported by validateHarvestPoints.
+const (
+	CheckTierPercent   = "tier_percent"
+	CheckUnknownTier   = "unknown_tier"
+	CheckDuplicateItem = "duplicate_item"
+	CheckTotalPercent  = "total_percent"
+	CheckItemName      = "item_name"
+	CheckAbundance     = "abundance"
+)
+
+// Band is the inclusive percent range an abundance tier is expected in.
+type Band struct {
+	Min float64 `json:"Min"`
+	Max float64 `json:"Max"`
+}
+
+// defaultBands are the tier ranges seen across the existing gathering data.
+var defaultBands = map[string]Band{
+	"Common":         {Min: 15, Max: 100},
+	"Uncommon":       {Min: 10, Max: 15},
+	"Rare":           {Min: 5, Max: 10},
+	"Very_Rare":      {Min: 1, Max: 5},
+	"Extremely_Rare": {Min: 0, Max: 1},
+}
+
+// Tiers that describe how an item is obtained rather than how often.
+var rateLessTiers = map[string]bool{
+	"Received_with_quest_active": true,
+}
+
+// friendlyNameRe matches the FriendlyName convention: words joined by single
+// underscores without spaces, starting with an upper case letter or digit.
+var friendlyNameRe = regexp.MustCompile(`^[A-Z0-9][^\s_]*(_[^\s_]+)*$`)
+
+// ValidationIssue is one problem found in the gathering input.
+type ValidationIssue struct {
+	Zone      string    `json:"Zone"`
+	PointType PointType `json:"PointType"`
+	Item      string    `json:"Item,omitempty"`
+	Check     string    `json:"Check"`
+	Message   string    `json:"Message"`
+}
+
+// ValidationReport is the machine readable result of validateHarvestPoints.
+type ValidationReport struct {
+	Zones  int               `json:"Zones"`
+	Items  int               `json:"Items"`
+	Issues []ValidationIssue `json:"Issues"`
+}
+
+// validateHarvestPoints checks tiers against bands, duplicate items, per point
+// percentage totals and item names. Issues are sorted by zone.
+func validateHarvestPoints(inputJSON []byte, defaultType PointType, bands map[string]Band) (ValidationReport, error) {
+	zones, err := parseZones(inputJSON, defaultType)
+	if err != nil {
+		return ValidationReport{}, err
+	}
+
+	report := ValidationReport{Zones: len(zones), Issues: []ValidationIssue{}}
+	for zone, itemsByType := range zones {
+		for _, pointType := range pointTypes {
+			items, ok := itemsByType[pointType.Type]
+			if !ok {
+				continue
+			}
+			report.Items += len(items)
+			report.Issues = append(report.Issues, validateItems(zone, pointType.Type, items, bands)...)
+		}
+	}
+
+	sort.SliceStable(report.Issues, func(i, j int) bool {
+		return report.Issues[i].Zone < report.Issues[j].Zone
+	})
+	return report, nil
+}
+
+// validateItems validates the items of one gathering point.
+func validateItems(zone string, pointType PointType, items []HarvestItem, bands map[string]Band) []ValidationIssue {
+	var issues []ValidationIssue
+	addIssue := func(item, check, format string, args ...interface{}) {
+		issues = append(issues, ValidationIssue{
+			Zone:      zone,
+			PointType: pointType,
+			Item:      item,
+			Check:     check,
+			Message:   fmt.Sprintf(format, args...),
+		})
+	}
+
+	seen := make(map[string]bool)
+	var total float64
+	for _, item := range items {
+		if !friendlyNameRe.MatchString(item.Item) {
+			addIssue(item.Item, CheckItemName, "item name %q does not follow the FriendlyName underscore convention", item.Item)
+		}
+
+		key := strings.ToLower(item.Item)
+		if seen[key] {
+			addIssue(item.Item, CheckDuplicateItem, "item %s is listed more than once", item.Item)
+		}
+		seen[key] = true
+
+		abundance, err := parseAbundance(item.Abundance)
+		if err != nil {
+			addIssue(item.Item, CheckAbundance, "%v", err)
+			continue
+		}
+		if abundance.Percent == nil {
+			if _, ok := bands[abundance.Tier]; !ok && !rateLessTiers[abundance.Tier] {
+				addIssue(item.Item, CheckUnknownTier, "unknown tier %s", abundance.Tier)
+			}
+			continue
+		}
+		total += *abundance.Percent
+
+		band, ok := bands[abundance.Tier]
+		if !ok {
+			addIssue(item.Item, CheckUnknownTier, "unknown tier %s", abundance.Tier)
+			continue
+		}
+		if *abundance.Percent < band.Min || *abundance.Percent > band.Max {
+			addIssue(item.Item, CheckTierPercent, "%s is outside the %s band %v%%-%v%%", item.Abundance, abundance.Tier, band.Min, band.Max)
+		}
+	}
+
+	// Allow for the rounding of the source percentages
+	if total > 100.5 {
+		addIssue("", CheckTotalPercent, "percentages add up to %.1f%%", total)
+	}
+
+	return issues
+}
+
+// loadBands reads tier bands from a JSON file of tier -> {Min, Max}.
+func loadBands(filename string) (map[string]Band, error) {
+	fileContent, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var bands map[string]Band
+	err = json.Unmarshal(fileContent, &bands)
+	if err != nil {
+		return nil, err
+	}
+
+	return bands, nil
+}
@@ -0,0 +1,73 @@
+package harvestpoints
+
+import (
+	"encoding/json"
+	"ffxi/transform"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidateHarvestPoints(t *testing.T) {
+	inputJSON := []byte(`{
+		"Giddeus": [
+			{"Item": "Moko_Grass", "Abundance": "Uncommon(10.3%)"},
+			{"Item": "Flax_Flower", "Abundance": "Common(4%)"},
+			{"Item": "Moko_Grass", "Abundance": "Uncommon(12%)"},
+			{"Item": "Grain Seeds", "Abundance": "Very_Rare(2.3%)"},
+			{"Item": "Mistroot", "Abundance": "Received_with_quest_active"},
+			{"Item": "Puffball", "Abundance": "Unknown(0%) (Sometimes)"}
+		],
+		"Zeruhn_Mines": {
+			"mining": [
+				{"Item": "Copper_Ore", "Abundance": "Common(60%)"},
+				{"Item": "Iron_Ore", "Abundance": "Common(50%)"}
+			]
+		}
+	}`)
+
+	report, err := validateHarvestPoints(inputJSON, Harvesting, defaultBands)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var checks []string
+	for _, issue := range report.Issues {
+		checks = append(checks, issue.Zone+" "+issue.Item+" "+issue.Check)
+	}
+	expected := []string{
+		"Giddeus Flax_Flower tier_percent",
+		"Giddeus Moko_Grass duplicate_item",
+		"Giddeus Grain Seeds item_name",
+		"Giddeus Puffball unknown_tier",
+		"Zeruhn_Mines  total_percent",
+	}
+	if !reflect.DeepEqual(checks, expected) {
+		t.Errorf("Expected issues %v, but got %v", expected, checks)
+	}
+	if report.Zones != 2 || report.Items != 8 {
+		t.Errorf("Expected 2 zones and 8 items, but got %d and %d", report.Zones, report.Items)
+	}
+}
+
+func TestValidateWithoutIssues(t *testing.T) {
+	dir := t.TempDir()
+	inputJSON := `{"Giddeus": [{"Item": "Moko_Grass", "Abundance": "Common(60%)"}]}`
+	if err := os.WriteFile(filepath.Join(dir, "input.json"), []byte(inputJSON), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := Validate(transform.Options{InputDir: dir}, DefaultConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reportJSON, err := json.Marshal(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(reportJSON), `"Issues":[]`) {
+		t.Errorf("Expected an empty issue list, but got %s", reportJSON)
+	}
+}
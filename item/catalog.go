@@ -0,0 +1,375 @@
+// Package item joins recipes, merchants, mob drops and gathering points into
+// a catalog of every item and how to get it.
+package item
+
+import (
+	"encoding/json"
+	"ffxi/harvestpoints"
+	"ffxi/merchants"
+	"ffxi/mobdrops"
+	"ffxi/recipe"
+	"os"
+	"sort"
+	"strings"
+)
+
+// RecipeRef points at a recipe that makes or uses an item.
+type RecipeRef struct {
+	Recipe           string `json:"Recipe"`
+	Craft            string `json:"Craft"`
+	Level            int    `json:"Level"`
+	Count            int    `json:"Count"`
+	HighQualityLevel int    `json:"HighQualityLevel,omitempty"`
+}
+
+// VendorRef points at a merchant selling an item.
+type VendorRef struct {
+	Merchant        string `json:"Merchant"`
+	Zone            string `json:"Zone"`
+	MinPrice        int    `json:"MinPrice"`
+	MaxPrice        int    `json:"MaxPrice"`
+	RankRequirement string `json:"RankRequirement,omitempty"`
+}
+
+// MobRef points at a mob dropping an item.
+type MobRef struct {
+	Mob            string               `json:"Mob"`
+	Zone           string               `json:"Zone"`
+	LevelRange     *mobdrops.LevelRange `json:"LevelRange"`
+	Percent        *float64             `json:"Percent"`
+	Source         string               `json:"Source,omitempty"`
+	AmountDefeated int                  `json:"AmountDefeated,omitempty"`
+}
+
+// GatheringRef points at a gathering point yielding an item.
+type GatheringRef struct {
+	Zone         string   `json:"Zone"`
+	PointType    string   `json:"PointType"`
+	RequiredTool string   `json:"RequiredTool"`
+	Percent      *float64 `json:"Percent"`
+	Tier         string   `json:"Tier,omitempty"`
+}
+
+// MarketPrice is the auction house price of an item. A nil price means there
+// is no fresh observation for it.
+type MarketPrice struct {
+	Single       *float64 `json:"Single"`
+	Stack        *float64 `json:"Stack"`
+	Observations int      `json:"Observations"`
+	Date         string   `json:"Date"`
+}
+
+// Entry is one item with every spelling and source it was seen in.
+type Entry struct {
+	Name            string         `json:"Name"`
+	Aliases         []string       `json:"Aliases,omitempty"`
+	ItemDBID        int            `json:"ItemDBID,omitempty"`
+	MadeBy          []RecipeRef    `json:"MadeBy,omitempty"`
+	UsedBy          []RecipeRef    `json:"UsedBy,omitempty"`
+	Vendors         []VendorRef    `json:"Vendors,omitempty"`
+	Mobs            []MobRef       `json:"Mobs,omitempty"`
+	GatheringPoints []GatheringRef `json:"GatheringPoints,omitempty"`
+	Market          *MarketPrice   `json:"Market,omitempty"`
+
+	nameRank int
+}
+
+// Rank of each source when picking the canonical spelling, lowest wins.
+// Recipes and merchants are properly capitalized, harvest names use
+// underscores and drop names are lower case.
+const (
+	rankRecipe = iota
+	rankMerchant
+	rankGathering
+	rankMob
+	rankUnknown
+)
+
+// Catalog joins items across datasets by Key.
+type Catalog struct {
+	entries map[string]*Entry
+}
+
+// NewCatalog returns an empty catalog.
+func NewCatalog() *Catalog {
+	return &Catalog{entries: make(map[string]*Entry)}
+}
+
+// Sources lists the transformer output files a catalog is built from.
+type Sources struct {
+	Recipes         []string
+	Merchants       []string
+	Mobs            []string
+	GatheringPoints []string
+}
+
+// Build reads every source file into a new catalog. Recipe files may hold a
+// single recipe or a list of them.
+func Build(sources Sources) (*Catalog, error) {
+	catalog := NewCatalog()
+
+	for _, path := range sources.Recipes {
+		var recipes []recipe.CraftingRecipe
+		if err := LoadList(path, &recipes); err != nil {
+			return nil, err
+		}
+		catalog.AddRecipes(recipes)
+	}
+	for _, path := range sources.Merchants {
+		var merchantList []merchants.MerchantInfo
+		if err := LoadList(path, &merchantList); err != nil {
+			return nil, err
+		}
+		catalog.AddMerchants(merchantList)
+	}
+	for _, path := range sources.Mobs {
+		var mobs []mobdrops.MobInfo
+		if err := LoadList(path, &mobs); err != nil {
+			return nil, err
+		}
+		catalog.AddMobs(mobs)
+	}
+	for _, path := range sources.GatheringPoints {
+		var points []harvestpoints.HarvestPoint
+		if err := LoadList(path, &points); err != nil {
+			return nil, err
+		}
+		catalog.AddGatheringPoints(points)
+	}
+
+	return catalog, nil
+}
+
+// LoadList reads a JSON list from path into v, accepting a single object as a
+// list of one.
+func LoadList(path string, v interface{}) error {
+	fileContent, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	trimmed := strings.TrimSpace(string(fileContent))
+	if strings.HasPrefix(trimmed, "{") {
+		trimmed = "[" + trimmed + "]"
+	}
+	return json.Unmarshal([]byte(trimmed), v)
+}
+
+// AddRecipes records the results and ingredients of recipes, including the
+// crystal, and the itemdb IDs of the results where the scrape linked them.
+func (c *Catalog) AddRecipes(recipes []recipe.CraftingRecipe) {
+	for _, r := range recipes {
+		ref := RecipeRef{Recipe: r.Name, Craft: r.MainCraft, Level: r.SkillLevels[r.MainCraft]}
+
+		results := r.AllPossibleResults
+		if len(results) == 0 {
+			results = []recipe.ResultsIncludingHighQuality{{Name: r.Result, Count: 1}}
+		}
+		for _, result := range results {
+			made := ref
+			made.Count = result.Count
+			made.HighQualityLevel = result.HighQualityLevel
+			entry := c.add(result.Name, rankRecipe)
+			entry.MadeBy = append(entry.MadeBy, made)
+			entry.setItemDBID(resultLink(r, result))
+		}
+
+		for _, ingredient := range r.RequiredItems {
+			used := ref
+			used.Count = ingredient.Count
+			entry := c.add(ingredient.Name, rankRecipe)
+			entry.UsedBy = append(entry.UsedBy, used)
+		}
+		if r.Crystal != "" {
+			used := ref
+			used.Count = 1
+			entry := c.add(CrystalName(r.Crystal), rankRecipe)
+			entry.UsedBy = append(entry.UsedBy, used)
+		}
+	}
+}
+
+// resultLink returns the itemdb link of a recipe result from its provenance.
+// The scrape links the NQ result, then each HQ result in order, so HQ links
+// are only used when there is one for every HQ result.
+func resultLink(r recipe.CraftingRecipe, result recipe.ResultsIncludingHighQuality) string {
+	if r.Provenance == nil {
+		return ""
+	}
+	if result.HighQualityLevel == 0 {
+		return r.Provenance.SourceURL
+	}
+	highQualityResults := 0
+	for _, other := range r.AllPossibleResults {
+		if other.HighQualityLevel > 0 {
+			highQualityResults++
+		}
+	}
+	if len(r.Provenance.Links) != highQualityResults || result.HighQualityLevel > len(r.Provenance.Links) {
+		return ""
+	}
+	return r.Provenance.Links[result.HighQualityLevel-1]
+}
+
+// CrystalName turns a recipe crystal like "Earth" into its item name.
+func CrystalName(crystal string) string {
+	if strings.HasSuffix(strings.ToLower(crystal), "crystal") {
+		return crystal
+	}
+	return crystal + " Crystal"
+}
+
+// AddMerchants records the goods merchants sell.
+func (c *Catalog) AddMerchants(merchantList []merchants.MerchantInfo) {
+	for _, merchant := range merchantList {
+		for _, good := range merchant.Items {
+			entry := c.add(good.Name, rankMerchant)
+			entry.Vendors = append(entry.Vendors, VendorRef{
+				Merchant:        merchant.Name,
+				Zone:            merchant.Zone,
+				MinPrice:        good.MinPrice,
+				MaxPrice:        good.MaxPrice,
+				RankRequirement: good.RankRequirement,
+			})
+		}
+	}
+}
+
+// AddMobs records mob drops, and the itemdb IDs of the dropped items from
+// the scrape pages their rates came from.
+func (c *Catalog) AddMobs(mobs []mobdrops.MobInfo) {
+	for _, mob := range mobs {
+		for _, drop := range mob.ItemDrops {
+			entry := c.add(drop.Name, rankMob)
+			if drop.Provenance != nil {
+				entry.setItemDBID(drop.Provenance.SourceURL)
+			}
+			entry.Mobs = append(entry.Mobs, MobRef{
+				Mob:            mob.Name,
+				Zone:           mob.ZoneName,
+				LevelRange:     mob.LevelRange,
+				Percent:        drop.Percent,
+				Source:         drop.Source,
+				AmountDefeated: drop.AmountDefeated,
+			})
+		}
+	}
+}
+
+// AddGatheringPoints records gathering yields. Count view files have no
+// Percent, so it is derived from the synthetic counts by YieldPercent.
+func (c *Catalog) AddGatheringPoints(points []harvestpoints.HarvestPoint) {
+	for _, point := range points {
+		for _, yield := range point.ItemDropInfos {
+			name := yield.FriendlyName
+			if name == "" {
+				name = yield.Name
+			}
+			entry := c.add(name, rankGathering)
+			entry.GatheringPoints = append(entry.GatheringPoints, GatheringRef{
+				Zone:         point.ZoneName,
+				PointType:    string(point.PointType),
+				RequiredTool: point.RequiredTool,
+				Percent:      point.YieldPercent(yield),
+				Tier:         yield.Tier,
+			})
+		}
+	}
+}
+
+// Add returns the entry of an item, adding it if needed.
+func (c *Catalog) Add(name string) *Entry {
+	return c.add(name, rankUnknown)
+}
+
+// SetItemDBID records the itemdb ID of an item, adding it if needed.
+func (c *Catalog) SetItemDBID(name string, id int) {
+	c.add(name, rankUnknown).ItemDBID = id
+}
+
+// setItemDBID records the ID of an itemdb link unless the entry has one.
+func (e *Entry) setItemDBID(url string) {
+	if id, ok := ParseItemDBID(url); ok && e.ItemDBID == 0 {
+		e.ItemDBID = id
+	}
+}
+
+// SetMarketPrice records the auction house price of an item, adding it if needed.
+func (c *Catalog) SetMarketPrice(name string, price MarketPrice) {
+	c.add(name, rankUnknown).Market = &price
+}
+
+// ObtainedBy returns how an item is obtained besides crafting, as the
+// recipe.Obtained ways, for styling dependency graphs.
+func (c *Catalog) ObtainedBy(name string) []string {
+	entry, ok := c.Lookup(name)
+	if !ok {
+		return nil
+	}
+
+	var obtained []string
+	if len(entry.Vendors) > 0 {
+		obtained = append(obtained, recipe.ObtainedVendor)
+	}
+	if len(entry.Mobs) > 0 {
+		obtained = append(obtained, recipe.ObtainedDrop)
+	}
+	if len(entry.GatheringPoints) > 0 {
+		obtained = append(obtained, recipe.ObtainedGathered)
+	}
+	return obtained
+}
+
+// Lookup finds an item by any of its spellings.
+func (c *Catalog) Lookup(name string) (*Entry, bool) {
+	entry, ok := c.entries[Key(name)]
+	return entry, ok
+}
+
+// Entries returns every item sorted by name.
+func (c *Catalog) Entries() []*Entry {
+	var entries []*Entry
+	for _, entry := range c.entries {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name < entries[j].Name
+	})
+	return entries
+}
+
+// add returns the entry for name, creating it if needed, and records the
+// spelling. A spelling from a better ranked source becomes the canonical name.
+func (c *Catalog) add(name string, rank int) *Entry {
+	key := Key(name)
+	spelling := strings.Join(strings.Fields(name), " ")
+	candidate := DisplayName(unitPrefixRe.ReplaceAllString(spelling, ""))
+	if rank == rankMob || rank == rankUnknown {
+		candidate = titleCase(Key(name))
+	}
+
+	entry, ok := c.entries[key]
+	if !ok {
+		entry = &Entry{Name: candidate, nameRank: rank}
+		c.entries[key] = entry
+	} else if rank < entry.nameRank {
+		entry.addAlias(entry.Name)
+		entry.Name = candidate
+		entry.nameRank = rank
+	}
+	entry.addAlias(spelling)
+	return entry
+}
+
+func (e *Entry) addAlias(alias string) {
+	if alias == e.Name {
+		return
+	}
+	for _, existing := range e.Aliases {
+		if existing == alias {
+			return
+		}
+	}
+	e.Aliases = append(e.Aliases, alias)
+	sort.Strings(e.Aliases)
+}
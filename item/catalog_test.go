@@ -0,0 +1,154 @@
+package item
+
+import (
+	"ffxi/harvestpoints"
+	"ffxi/merchants"
+	"ffxi/mobdrops"
+	"ffxi/recipe"
+	"ffxi/transform"
+	"reflect"
+	"testing"
+)
+
+func TestKey(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected string
+	}{
+		{"Grain Seeds", "grain seeds"},
+		{"Grain_Seeds", "grain seeds"},
+		{"rock salt", "rock salt"},
+		{"chunk of rock salt", "rock salt"},
+		{"suit of Goblin armor", "goblin armor"},
+		{"Dyer's_Woad", "dyer's woad"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := Key(tc.name)
+			if result != tc.expected {
+				t.Errorf("Expected %s, but got %s", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestCatalog(t *testing.T) {
+	catalog := NewCatalog()
+	catalog.AddMobs([]mobdrops.MobInfo{
+		{Name: "Snipper", ZoneName: "Valkurm_Dunes", ItemDrops: []mobdrops.ItemDrop{{Name: "chunk of rock salt"}}},
+	})
+	catalog.AddGatheringPoints([]harvestpoints.HarvestPoint{
+		{PointType: "harvesting", ZoneName: "Giddeus", TotalKnownDefeated: 100,
+			ItemDropInfos: []harvestpoints.ItemDropInfo{{Name: "Grain Seeds", FriendlyName: "Grain_Seeds", TotalKnownDrops: 2}}},
+	})
+	catalog.AddMerchants([]merchants.MerchantInfo{
+		{Name: "Brunhilde", Zone: "Bastok_Markets", Items: []merchants.ItemInfo{{Name: "Rock Salt", MinPrice: 4, MaxPrice: 5}}},
+	})
+	catalog.AddRecipes([]recipe.CraftingRecipe{
+		{
+			Name:          "Cooking-10-Salt-From-1-Rock Salt",
+			Result:        "Salt",
+			Crystal:       "Fire",
+			MainCraft:     "Cooking",
+			SkillLevels:   map[string]int{"Cooking": 10},
+			RequiredItems: []recipe.Item{{Name: "Rock Salt", Count: 1}, {Name: "Grain Seeds", Count: 2}},
+		},
+	})
+	catalog.SetItemDBID("Rock_Salt", 936)
+
+	rockSalt, ok := catalog.Lookup("Rock_Salt")
+	if !ok {
+		t.Fatal("Expected Rock Salt in the catalog")
+	}
+	// The drop spelling came first but the merchant spelling replaces it
+	if rockSalt.Name != "Rock Salt" {
+		t.Errorf("Expected Rock Salt, but got %s", rockSalt.Name)
+	}
+	expectedAliases := []string{"Rock_Salt", "chunk of rock salt"}
+	if !reflect.DeepEqual(rockSalt.Aliases, expectedAliases) {
+		t.Errorf("Expected aliases %v, but got %v", expectedAliases, rockSalt.Aliases)
+	}
+	if rockSalt.ItemDBID != 936 || len(rockSalt.Mobs) != 1 || len(rockSalt.Vendors) != 1 || len(rockSalt.UsedBy) != 1 {
+		t.Errorf("Expected an ID and one mob, vendor and recipe, but got %+v", rockSalt)
+	}
+
+	grainSeeds, _ := catalog.Lookup("grain seeds")
+	if grainSeeds.Name != "Grain Seeds" || len(grainSeeds.GatheringPoints) != 1 || *grainSeeds.GatheringPoints[0].Percent != 2 {
+		t.Errorf("Unexpected grain seeds entry %+v", grainSeeds)
+	}
+
+	if _, ok := catalog.Lookup("Fire Crystal"); !ok {
+		t.Error("Expected the recipe crystal in the catalog")
+	}
+	if salt, _ := catalog.Lookup("Salt"); salt == nil || len(salt.MadeBy) != 1 {
+		t.Error("Expected Salt to be made by one recipe")
+	}
+}
+
+func TestCatalogItemDBIDs(t *testing.T) {
+	catalog := NewCatalog()
+	catalog.AddRecipes([]recipe.CraftingRecipe{
+		{
+			Name:          "Clothcraft-17-Windurstian Tekko-From-1-Grass Thread",
+			Result:        "Windurstian Tekko",
+			MainCraft:     "Clothcraft",
+			SkillLevels:   map[string]int{"Clothcraft": 17},
+			RequiredItems: []recipe.Item{{Name: "Grass Thread", Count: 1}},
+			AllPossibleResults: []recipe.ResultsIncludingHighQuality{
+				{Name: "Federation Tekko", Count: 1, HighQualityLevel: 1},
+				{Name: "Windurstian Tekko", Count: 1},
+			},
+			Provenance: &transform.Provenance{SourceURL: "http://ffxi.somepage.com/itemdb/2536", Links: []string{"http://ffxi.somepage.com/itemdb/2537"}},
+		},
+	})
+	catalog.AddMobs([]mobdrops.MobInfo{
+		{Name: "Bogy", ZoneName: "Valkurm_Dunes", ItemDrops: []mobdrops.ItemDrop{
+			{Name: "bloody robe", Provenance: &transform.Provenance{SourceURL: "http://www.ffxidb.com/items/540"}},
+			{Name: "ash log"},
+		}},
+	})
+
+	testCases := []struct {
+		name     string
+		expected int
+	}{
+		{"Windurstian Tekko", 2536},
+		{"Federation Tekko", 2537},
+		{"Bloody Robe", 540},
+		{"Grass Thread", 0},
+		{"Ash Log", 0},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			entry, ok := catalog.Lookup(tc.name)
+			if !ok {
+				t.Fatalf("Expected %s in the catalog", tc.name)
+			}
+			if entry.ItemDBID != tc.expected {
+				t.Errorf("Expected itemdb ID %d, but got %d", tc.expected, entry.ItemDBID)
+			}
+		})
+	}
+}
+
+func TestParseItemDBID(t *testing.T) {
+	testCases := []struct {
+		url      string
+		expected int
+		ok       bool
+	}{
+		{"http://ffxi.somepage.com/itemdb/2536", 2536, true},
+		{"http://www.ffxidb.com/items/540", 540, true},
+		{"http://www.ffxidb.com/zones/104/bogy", 0, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.url, func(t *testing.T) {
+			id, ok := ParseItemDBID(tc.url)
+			if id != tc.expected || ok != tc.ok {
+				t.Errorf("Expected %d (%v), but got %d (%v)", tc.expected, tc.ok, id, ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package item
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Unit words the drop scrapes put in front of item names, as in
+// "chunk of rock salt" or "suit of Goblin armor".
+var unitPrefixRe = regexp.MustCompile(`^(?:an? )?(?:bag|block|bolt|bottle|bowl|bunch|bundle|chunk|clump|cluster|flask|handful|head|jar|loaf|lump|pair|phial|piece|pinch|pot|pouch|roll|sack|set|sheet|slice|spool|sprig|square|strip|suit|vial) of `)
+
+// Words left lower case when a name has to be title cased.
+var lowerCaseWords = map[string]bool{"of": true, "the": true, "and": true, "a": true, "an": true}
+
+// Key normalizes an item name for joins across datasets. Case, underscores
+// and unit prefixes are ignored, so "Rock_Salt", "rock salt" and
+// "chunk of rock salt" share a key.
+func Key(name string) string {
+	name = strings.ToLower(strings.ReplaceAll(name, "_", " "))
+	name = strings.Join(strings.Fields(name), " ")
+	return unitPrefixRe.ReplaceAllString(name, "")
+}
+
+// DisplayName turns a FriendlyName like Grain_Seeds into Grain Seeds.
+func DisplayName(name string) string {
+	return strings.Join(strings.Fields(strings.ReplaceAll(name, "_", " ")), " ")
+}
+
+// titleCase upper cases the first letter of each word of a drop name that
+// has no better spelling, e.g. "rock salt" becomes "Rock Salt".
+func titleCase(name string) string {
+	words := strings.Fields(name)
+	for i, word := range words {
+		if i > 0 && lowerCaseWords[word] {
+			continue
+		}
+		words[i] = strings.ToUpper(word[:1]) + word[1:]
+	}
+	return strings.Join(words, " ")
+}
+
+var itemDBIDRe = regexp.MustCompile(`/(?:itemdb|items)/(\d+)`)
+
+// ParseItemDBID extracts the item ID from itemdb links like
+// http://ffxi.somepage.com/itemdb/2536 or http://www.ffxidb.com/items/540.
+func ParseItemDBID(url string) (int, bool) {
+	match := itemDBIDRe.FindStringSubmatch(url)
+	if len(match) < 2 {
+		return 0, false
+	}
+	id, err := strconv.Atoi(match[1])
+	if err != nil {
+		return 0, false
+	}
+	return id, true
+}
@@ -2,132 +2,616 @@ package main
 
 import (
 	"encoding/json"
+	"ffxi/api"
+	"ffxi/auction"
+	"ffxi/dataset"
+	"ffxi/diff"
+	"ffxi/export"
+	"ffxi/harvestpoints"
+	"ffxi/item"
+	"ffxi/merchants"
+	"ffxi/migrate"
+	"ffxi/mobdrops"
+	"ffxi/pipeline"
+	"ffxi/profit"
 	"ffxi/recipe"
+	"ffxi/schema"
+	"ffxi/transform"
+	"flag"
 	"fmt"
+	"io"
 	"log"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
 )
 
+// Defaults of the recipes command: the bundled guild recipe scrape, written
+// to one file per recipe under recipes.
+const (
+	defaultRecipesInputDir  = "."
+	defaultRecipesFile      = "input.json"
+	defaultRecipesOutputDir = "recipes"
+)
+
+const usage = `usage: ffxi <command> [flags]
+
+commands:
+  recipes    transform the guild recipe scrape into recipe files
+  merchants  transform the merchant scrape into per zone merchant files
+  drops      merge mob drop scrapes and update zone drop rates
+  harvest    transform harvest input into gathering point files
+  all        run merchants, drops, harvest and recipes with their defaults
+  build      run the data build described by a pipeline config file
+  export     export the transformed datasets as SQL, CSV/TSV, a Markdown wiki or RAG documents
+  serve      serve the transformed datasets as a read-only HTTP JSON API
+  graph      write a Graphviz DOT crafting dependency graph
+  profit     write crafting profit reports per craft and level band
+  schema     write the JSON Schemas of the output formats
+  validate   check output files and directories against the JSON Schemas
+  migrate    upgrade output files of older versions to the current format
+  diff       compare two output trees by entity
+
+Run ffxi <command> -h for the flags of a command.
+`
+
 func main() {
-	//fileName := "Goldsmithing Guild Recipes _Synthesis_ - Final Fantasy XI - somepage.com.json"
-	//fileName := "clothcraft Guild Recipes _Synthesis_ - Final Fantasy XI - somepage.com.json"
-	//fileName := "Leatherworking Guild Recipes _Synthesis_ - Final Fantasy XI - somepage.com.json"
-	fileName := "smithing and woodworking Guild Recipes _Synthesis_ - Final Fantasy XI - somepage.com"
-	filePath := fmt.Sprintf("/Users/russellboley/Documents/%s", fileName)
-	inputJSON, err := os.ReadFile(filePath)
+	log.SetFlags(0)
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	command, args := os.Args[1], os.Args[2:]
+	var err error
+	switch command {
+	case "recipes":
+		err = recipesCommand(args)
+	case "merchants":
+		err = merchantsCommand(args)
+	case "drops":
+		err = dropsCommand(args)
+	case "harvest":
+		err = harvestCommand(args)
+	case "all":
+		err = allCommand(args)
+	case "build":
+		err = buildCommand(args)
+	case "export":
+		err = exportCommand(args)
+	case "serve":
+		err = serveCommand(args)
+	case "graph":
+		err = graphCommand(args)
+	case "profit":
+		err = profitCommand(args)
+	case "schema":
+		err = schemaCommand(args)
+	case "validate":
+		err = validateCommand(args)
+	case "migrate":
+		err = migrateCommand(args)
+	case "diff":
+		err = diffCommand(args)
+	case "-h", "-help", "--help", "help":
+		fmt.Print(usage)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", command, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatalf("%s: %v", command, err)
+	}
+}
+
+// commonFlags registers the flags every command shares. The directory
+// defaults are where each transformer has always read and written.
+func commonFlags(fs *flag.FlagSet, inputDir, outputDir, overwrite string) *transform.Options {
+	opts := &transform.Options{}
+	fs.StringVar(&opts.InputDir, "in", inputDir, "input directory")
+	fs.StringVar(&opts.OutputDir, "out", outputDir, "output directory")
+	fs.StringVar(&opts.Format, "format", transform.FormatJSON, "output format: json, csv or tsv")
+	fs.StringVar(&opts.Overwrite, "overwrite", overwrite, "existing output files: replace, skip or merge")
+	fs.BoolVar(&opts.Verbose, "v", false, "log progress")
+	return opts
+}
+
+func recipesCommand(args []string) error {
+	fs := flag.NewFlagSet("recipes", flag.ExitOnError)
+	opts := commonFlags(fs, defaultRecipesInputDir, defaultRecipesOutputDir, transform.OverwriteSkip)
+	file := fs.String("file", defaultRecipesFile, "recipe scrape file in the input directory")
+	summaryDir := fs.String("summary", ".", "directory for all_craft.json and item_names.txt")
+	fs.Parse(args)
+	opts.Inputs = []string{*file}
+
+	err := recipe.Run(*opts, *summaryDir)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
+	fmt.Println("Transformation complete. Output written to all_craft.json")
+	return nil
+}
 
-	craftingRecipes, err := recipe.TransformRecipes(string(inputJSON))
+func merchantsCommand(args []string) error {
+	fs := flag.NewFlagSet("merchants", flag.ExitOnError)
+	opts := commonFlags(fs, "merchants", filepath.Join("merchants", "merchants"), transform.OverwriteReplace)
+	fs.Parse(args)
+
+	err := merchants.Run(*opts)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
+	fmt.Println("Merchant files written successfully.")
+	return nil
+}
 
-	allRecipeFilePath := "/Users/russellboley/git/FantasyAi/FinalFantasyData/CraftingRecipes/not_ready_for_yet"
-	os.Mkdir(allRecipeFilePath, 0777)
-	for _, recipe := range craftingRecipes {
-		recipeJSON, err := json.MarshalIndent(recipe, "", "  ")
+func dropsCommand(args []string) error {
+	fs := flag.NewFlagSet("drops", flag.ExitOnError)
+	opts := commonFlags(fs, "mobdrops", "mobdrops", transform.OverwriteReplace)
+	zones := fs.String("zones", strings.Join(mobdrops.DefaultZones, ","), "comma separated zones whose <zone>.json mob files are updated")
+	fs.Parse(args)
+
+	err := mobdrops.Run(*opts, splitList(*zones))
+	if err != nil {
+		return err
+	}
+	fmt.Println("Drop files written successfully.")
+	return nil
+}
+
+func harvestCommand(args []string) error {
+	fs := flag.NewFlagSet("harvest", flag.ExitOnError)
+	opts := commonFlags(fs, "harvestpoints", filepath.Join("harvestpoints", "allHarvestPoints_output"), transform.OverwriteReplace)
+	view := fs.String("view", string(harvestpoints.DefaultConfig.View), "rate view to write: exact or counts")
+	pointType := fs.String("type", string(harvestpoints.DefaultConfig.PointType), "point type of zones listing items directly")
+	validate := fs.Bool("validate", false, "validate input.json and print a JSON report instead of transforming")
+	bandsFile := fs.String("bands", "", "JSON file of tier -> {Min, Max} percent bands used by -validate")
+	fs.Parse(args)
+
+	cfg := harvestpoints.Config{View: harvestpoints.View(*view), PointType: harvestpoints.PointType(*pointType), BandsFile: *bandsFile}
+	if *validate {
+		report, err := harvestpoints.Validate(*opts, cfg)
 		if err != nil {
-			log.Fatal(err)
+			return err
 		}
-
-		fileName, err := getShortFileName(recipe.Name)
+		reportJSON, err := json.MarshalIndent(report, "", "  ")
 		if err != nil {
-			log.Fatal(err)
+			return err
 		}
-		filePath := fmt.Sprintf("%s/%s.json", allRecipeFilePath, fileName)
+		fmt.Println(string(reportJSON))
+		if len(report.Issues) > 0 {
+			os.Exit(1)
+		}
+		return nil
+	}
 
-		_, err = os.Stat(filePath)
-		if err == nil {
-			continue
+	err := harvestpoints.Run(*opts, cfg)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Harvest point files written successfully.")
+	return nil
+}
+
+// allCommand runs every transformer with its default directories under -in
+// and -out, stopping at the first failure.
+func allCommand(args []string) error {
+	fs := flag.NewFlagSet("all", flag.ExitOnError)
+	opts := commonFlags(fs, ".", ".", transform.OverwriteReplace)
+	fs.Parse(args)
+
+	under := func(in, out string) transform.Options {
+		o := *opts
+		o.InputDir = filepath.Join(opts.InputDir, in)
+		o.OutputDir = filepath.Join(opts.OutputDir, out)
+		return o
+	}
+
+	if err := merchants.Run(under("merchants", filepath.Join("merchants", "merchants"))); err != nil {
+		return fmt.Errorf("merchants: %v", err)
+	}
+	if err := mobdrops.Run(under("mobdrops", "mobdrops"), nil); err != nil {
+		return fmt.Errorf("drops: %v", err)
+	}
+	if err := harvestpoints.Run(under("harvestpoints", filepath.Join("harvestpoints", "allHarvestPoints_output")), harvestpoints.DefaultConfig); err != nil {
+		return fmt.Errorf("harvest: %v", err)
+	}
+
+	recipeOpts := under(defaultRecipesInputDir, defaultRecipesOutputDir)
+	recipeOpts.Inputs = []string{defaultRecipesFile}
+	recipeOpts.Overwrite = transform.OverwriteSkip
+	if err := recipe.Run(recipeOpts, opts.OutputDir); err != nil {
+		return fmt.Errorf("recipes: %v", err)
+	}
+
+	fmt.Println("All transformations complete.")
+	return nil
+}
+
+func buildCommand(args []string) error {
+	fs := flag.NewFlagSet("build", flag.ExitOnError)
+	configFile := fs.String("config", "pipeline.json", "pipeline config file")
+	verbose := fs.Bool("v", false, "log progress")
+	fs.Parse(args)
+
+	cfg, err := pipeline.Load(*configFile)
+	if err != nil {
+		return err
+	}
+	err = cfg.Run(*verbose)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Build complete.")
+	return nil
+}
 
-			//filePath = addCharacterBeforeExtension(filePath, '2')
-			//log.Fatalf("File at path %s already exists", filePath)
+// sourceFlags registers the flags listing transformer output files, which
+// default to where the other commands write them.
+func sourceFlags(fs *flag.FlagSet) func() dataset.Sources {
+	recipes := fs.String("recipes", strings.Join(dataset.DefaultSources.Recipes, ","), "comma separated recipe files or globs")
+	merchantFiles := fs.String("merchants", strings.Join(dataset.DefaultSources.Merchants, ","), "comma separated merchant files or globs")
+	mobs := fs.String("drops", strings.Join(dataset.DefaultSources.Mobs, ","), "comma separated mob drop files or globs")
+	harvest := fs.String("harvest", strings.Join(dataset.DefaultSources.HarvestPoints, ","), "comma separated harvest point files or globs")
+	return func() dataset.Sources {
+		return dataset.Sources{
+			Recipes:       splitList(*recipes),
+			Merchants:     splitList(*merchantFiles),
+			Mobs:          splitList(*mobs),
+			HarvestPoints: splitList(*harvest),
 		}
+	}
+}
+
+func exportCommand(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	sources := sourceFlags(fs)
+	format := fs.String("format", "sql", "export format: sql, csv, tsv, wiki or rag")
+	output := fs.String("o", "", "output file for sql and rag, directory for the others (default ffxi.sql, export, wiki or ffxi.jsonl)")
+	fs.Parse(args)
+
+	switch *format {
+	case "sql", transform.FormatCSV, transform.FormatTSV, "wiki", "rag":
+	default:
+		return fmt.Errorf("unknown export format %q", *format)
+	}
+
+	d, err := dataset.Load(sources())
+	if err != nil {
+		return err
+	}
+
+	switch *format {
+	case "sql":
+		if *output == "" {
+			*output = "ffxi.sql"
+		}
+		err = writeFile(*output, func(w io.Writer) error { return export.WriteSQL(w, d) })
+	case "wiki":
+		if *output == "" {
+			*output = "wiki"
+		}
+		err = export.WriteWiki(*output, d)
+	case "rag":
+		if *output == "" {
+			*output = "ffxi.jsonl"
+		}
+		err = writeFile(*output, func(w io.Writer) error { return export.WriteRAG(w, d) })
+	default:
+		if *output == "" {
+			*output = "export"
+		}
+		err = export.WriteTables(*output, d, *format)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Exported %d recipes, %d merchants, %d mobs and %d harvest points to %s\n",
+		len(d.Recipes), len(d.Merchants), len(d.Mobs), len(d.HarvestPoints), *output)
+	return nil
+}
+
+func serveCommand(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	sources := sourceFlags(fs)
+	addr := fs.String("addr", "localhost:8080", "address to listen on")
+	pricesFile := fs.String("prices", "", "JSON or CSV item price table used to value recipe results")
+	auctionFile := fs.String("auction", "", "JSON or CSV auction house observations")
+	fs.Parse(args)
+
+	d, err := dataset.Load(sources())
+	if err != nil {
+		return err
+	}
+	catalog := d.Catalog()
+	prices, err := loadPrices(*pricesFile, *auctionFile, catalog)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Serving %d recipes, %d merchants, %d mobs and %d harvest points on http://%s (OpenAPI at /openapi.json)",
+		len(d.Recipes), len(d.Merchants), len(d.Mobs), len(d.HarvestPoints), *addr)
+	return http.ListenAndServe(*addr, api.New(d, catalog, prices))
+}
 
-		err = os.WriteFile(filePath, []byte(recipeJSON), 0644)
+// loadPrices chains the user price table over auction house prices, either
+// of which may be left out.
+func loadPrices(pricesFile, auctionFile string, catalog *item.Catalog) (profit.PriceChain, error) {
+	var prices profit.PriceChain
+	if pricesFile != "" {
+		table, err := profit.LoadPriceTable(pricesFile)
 		if err != nil {
-			log.Fatal(err)
+			return nil, err
 		}
+		prices = append(prices, table)
 	}
+	if auctionFile != "" {
+		observations, err := auction.Load(auctionFile)
+		if err != nil {
+			return nil, err
+		}
+		table := auction.NewTable(observations, auction.Rules{})
+		table.Attach(catalog)
+		prices = append(prices, table)
+	}
+	return prices, nil
+}
 
-	// Marshal the CraftingRecipe slice into JSON
-	outputJSON, err := json.MarshalIndent(craftingRecipes, "", "  ")
+func profitCommand(args []string) error {
+	fs := flag.NewFlagSet("profit", flag.ExitOnError)
+	sources := sourceFlags(fs)
+	pricesFile := fs.String("prices", "", "JSON or CSV item price table used to value recipe results")
+	auctionFile := fs.String("auction", "", "JSON or CSV auction house observations")
+	merchantPrice := fs.String("merchant-price", profit.DefaultMerchantPrice, "merchant price paid for ingredients: min, max or mid")
+	bandSize := fs.Int("band", profit.DefaultLevelBandSize, "size of the level bands reports are grouped by")
+	hqRates := fs.String("hq", "1:0.05,2:0.01,3:0.005", "chance of each HQ level per synth as level:chance pairs")
+	output := fs.String("o", "profit", "output directory")
+	fs.Parse(args)
+
+	switch *merchantPrice {
+	case profit.PriceMin, profit.PriceMax, profit.PriceMid:
+	default:
+		return fmt.Errorf("unknown merchant price %q, expected min, max or mid", *merchantPrice)
+	}
+	if *bandSize <= 0 {
+		return fmt.Errorf("level band size must be positive, got %d", *bandSize)
+	}
+	rates, err := profit.ParseHQRates(*hqRates)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 
-	err = os.WriteFile("all_craft.json", []byte(outputJSON), 0644)
+	d, err := dataset.Load(sources())
+	if err != nil {
+		return err
+	}
+	catalog := d.Catalog()
+	prices, err := loadPrices(*pricesFile, *auctionFile, catalog)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 
-	// Extract item names from the transformed JSON
-	var items []string
-	var recipes []recipe.CraftingRecipe
-	err = json.Unmarshal([]byte(outputJSON), &recipes)
+	opts := profit.Options{MerchantPrice: *merchantPrice, HQRates: rates, LevelBandSize: *bandSize}
+	rows := profit.Calculate(d.Recipes, catalog, prices, opts)
+	if err := profit.WriteReports(*output, rows); err != nil {
+		return err
+	}
+	fmt.Printf("Wrote profit reports of %d recipes to %s\n", len(rows), *output)
+	return nil
+}
+
+func graphCommand(args []string) error {
+	fs := flag.NewFlagSet("graph", flag.ExitOnError)
+	sources := sourceFlags(fs)
+	target := fs.String("item", "", "draw the recipes making this item and its ingredients")
+	craft := fs.String("craft", "", "draw the recipes of this main craft")
+	output := fs.String("o", "recipes.dot", "output file, - for stdout")
+	fs.Parse(args)
+
+	d, err := dataset.Load(sources())
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
+	opts := recipe.GraphOptions{Target: *target, Craft: *craft, ObtainedBy: d.Catalog().ObtainedBy}
 
-	uniqueItems := make(map[string]struct{})
+	if *output == "-" {
+		return recipe.WriteDOT(os.Stdout, d.Recipes, opts)
+	}
+	err = writeFile(*output, func(w io.Writer) error { return recipe.WriteDOT(w, d.Recipes, opts) })
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Graph written to %s\n", *output)
+	return nil
+}
+
+func schemaCommand(args []string) error {
+	fs := flag.NewFlagSet("schema", flag.ExitOnError)
+	output := fs.String("o", "schemas", "output directory")
+	fs.Parse(args)
 
-	for _, r := range recipes {
-		for _, i := range r.RequiredItems {
-			if _, ok := uniqueItems[i.Name]; !ok {
-				items = append(items, i.Name)
-				// Add the item name to the map
-				uniqueItems[i.Name] = struct{}{}
+	if err := os.MkdirAll(*output, os.ModePerm); err != nil {
+		return err
+	}
+	for _, kind := range schema.Kinds {
+		schemaJSON, err := json.MarshalIndent(kind.Schema(), "", "  ")
+		if err != nil {
+			return err
+		}
+		filename := filepath.Join(*output, kind.Name+".schema.json")
+		if err := os.WriteFile(filename, append(schemaJSON, '\n'), 0644); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("Wrote %d version %d schemas to %s\n", len(schema.Kinds), schema.Version, *output)
+	return nil
+}
+
+func validateCommand(args []string) error {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	kind := fs.String("kind", "", "output format of every file, detected per file when empty")
+	jsonReport := fs.Bool("json", false, "print a JSON report")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: ffxi validate [flags] path...")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if *kind != "" {
+		if _, ok := schema.Lookup(*kind); !ok {
+			var names []string
+			for _, k := range schema.Kinds {
+				names = append(names, k.Name)
 			}
+			return fmt.Errorf("unknown kind %q, expected one of %s", *kind, strings.Join(names, ", "))
 		}
 	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
 
-	// Write item names to a file
-	err = os.WriteFile("item_names.txt", []byte(strings.Join(items, "\n")), 0644)
-	if err != nil {
-		log.Fatal(err)
+	report := schema.Report{Issues: []schema.Issue{}}
+	for _, dir := range fs.Args() {
+		dirReport, err := schema.ValidateDir(dir, *kind)
+		if err != nil {
+			return err
+		}
+		report.Files += dirReport.Files
+		report.Invalid += dirReport.Invalid
+		report.Issues = append(report.Issues, dirReport.Issues...)
 	}
 
-	fmt.Println("Transformation complete. Output written to output.json")
+	if *jsonReport {
+		reportJSON, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(reportJSON))
+	} else {
+		for _, issue := range report.Issues {
+			fmt.Printf("%s: %s: %s\n", issue.File, issue.Path, issue.Message)
+		}
+		fmt.Printf("%d of %d files valid against the version %d schemas\n", report.Files-report.Invalid, report.Files, schema.Version)
+	}
+	if report.Invalid > 0 {
+		os.Exit(1)
+	}
+	return nil
 }
 
-func getShortFileName(fileName string) (string, error) {
-	// Extract everything before "-From" (non-inclusive)
-	index := strings.Index(fileName, ",")
-	if index == -1 {
-		// file name is probably pretty short
-		return fileName, nil
+func migrateCommand(args []string) error {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	output := fs.String("o", "", "directory to write the migrated files to, in place when empty")
+	dryRun := fs.Bool("n", false, "report what would be migrated without writing anything")
+	jsonReport := fs.Bool("json", false, "print a JSON report")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: ffxi migrate [flags] path...")
+		fs.PrintDefaults()
 	}
+	fs.Parse(args)
 
-	resultFilename := strings.TrimSpace(fileName[:index])
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
 
-	// Check if the resulting filename is empty
-	if resultFilename == "" {
-		log.Fatal("Invalid resulting filename")
+	results := []migrate.Result{}
+	for _, path := range fs.Args() {
+		pathResults, err := migrate.Path(path, *output, *dryRun)
+		if err != nil {
+			return err
+		}
+		results = append(results, pathResults...)
 	}
 
-	// Display the resulting filename
-	fmt.Println("Resulting filename:", resultFilename)
+	if *jsonReport {
+		reportJSON, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(reportJSON))
+		return nil
+	}
 
-	return resultFilename, nil
+	migrated := 0
+	for _, result := range results {
+		switch {
+		case result.Kind == "":
+			fmt.Printf("%s: %s\n", result.File, strings.Join(result.Notes, "; "))
+			continue
+		case result.Migrated:
+			migrated++
+			fmt.Printf("%s: %s version %d -> %d, %d records\n", result.File, result.Kind, result.FromVersion, schema.Version, result.Records)
+		}
+		for _, note := range result.Notes {
+			fmt.Printf("  %s\n", note)
+		}
+	}
+	fmt.Printf("Migrated %d of %d files to version %d\n", migrated, len(results), schema.Version)
+	return nil
 }
 
-// addCharacterBeforeExtension adds a character before the file extension.
-func addCharacterBeforeExtension(filePath string, char rune) string {
-	ext := filepath.Ext(filePath)
-	if ext == "" {
-		// No extension found
-		return filePath
+func diffCommand(args []string) error {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	threshold := fs.Float64("threshold", diff.DefaultThreshold, "smallest drop or harvest rate shift reported, in percentage points")
+	jsonReport := fs.Bool("json", false, "print a JSON report")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: ffxi diff [flags] old new")
+		fs.PrintDefaults()
 	}
+	fs.Parse(args)
 
-	// Find the position of the last dot before the extension
-	dotIndex := strings.LastIndex(filePath, ext)
+	if fs.NArg() != 2 {
+		fs.Usage()
+		os.Exit(2)
+	}
 
-	// Insert the character before the dot
-	newFilePath := filePath[:dotIndex] + string(char) + filePath[dotIndex:]
+	oldTree, err := dataset.LoadTree(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	newTree, err := dataset.LoadTree(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	report := diff.Compare(oldTree, newTree, diff.Options{Threshold: *threshold})
 
-	return newFilePath
+	if *jsonReport {
+		reportJSON, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(reportJSON))
+		return nil
+	}
+	return report.WriteText(os.Stdout)
+}
+
+// writeFile creates filename and writes it with write.
+func writeFile(filename string, write func(w io.Writer) error) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+
+	err = write(file)
+	closeErr := file.Close()
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
+
+func splitList(list string) []string {
+	var values []string
+	for _, value := range strings.Split(list, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
 }
@@ -0,0 +1,245 @@
+// Package merchants turns scraped merchant listings into per zone lists of the
+// goods each merchant sells.
+package merchants
+
+import (
+	"encoding/json"
+	"ffxi/transform"
+	"ffxi/zone"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Merchant is one row of the merchant scrape.
+type Merchant struct {
+	Merchant   string `json:"merchant"`
+	Type       string `json:"type"`
+	GoodsPrice string `json:"goodsPrice"`
+	Location   string `json:"location"`
+}
+
+// ItemInfo is a good sold by a merchant. Prices vary with fame between
+// MinPrice and MaxPrice, RankRequirement is set for conquest rank goods.
+type ItemInfo struct {
+	Name            string
+	MinPrice        int
+	MaxPrice        int
+	RankRequirement string
+}
+
+// MerchantInfo is a merchant with its goods, canonical zone ID and map
+// position within the zone, e.g. F-10. Provenance is the scrape row it was
+// read from.
+type MerchantInfo struct {
+	SchemaVersion int `json:"SchemaVersion"`
+	Name          string
+	Items         []ItemInfo
+	Zone          string
+	Position      string                `json:",omitempty"`
+	Provenance    *transform.Provenance `json:",omitempty"`
+}
+
+// Read reads a merchant scrape, a JSON list of Merchant rows.
+func Read(r io.Reader) ([]MerchantInfo, error) {
+	jsonData, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return ExtractMerchantInfo(jsonData)
+}
+
+// ExtractMerchantInfo parses a merchant scrape into merchants with their goods.
+func ExtractMerchantInfo(jsonData []byte) ([]MerchantInfo, error) {
+	var merchants []Merchant
+	if err := json.Unmarshal(jsonData, &merchants); err != nil {
+		return nil, err
+	}
+
+	var merchantInfoList []MerchantInfo
+	for i, merchant := range merchants {
+		goodsList, err := ExtractGoodsAndPrices(merchant.GoodsPrice)
+		if err != nil {
+			return nil, err
+		}
+		zone, err := ExtractZone(merchant.Location)
+		if err != nil {
+			return nil, err
+		}
+
+		merchantInfoList = append(merchantInfoList, MerchantInfo{
+			SchemaVersion: transform.SchemaVersion,
+			Name:          merchant.Merchant,
+			Items:         goodsList,
+			Zone:          zone,
+			Position:      ExtractPosition(merchant.Location),
+			Provenance:    &transform.Provenance{Row: i},
+		})
+	}
+
+	return merchantInfoList, nil
+}
+
+// ExtractGoodsAndPrices parses a goods listing like "Bronze Cap 154-174 gil"
+// into items. A single price sets both MinPrice and MaxPrice.
+func ExtractGoodsAndPrices(goodsPrice string) ([]ItemInfo, error) {
+	re := regexp.MustCompile(`([^\d]+) (\d+)(?:-(\d+))? gil`)
+	matches := re.FindAllStringSubmatch(goodsPrice, -1)
+
+	var items []ItemInfo
+	for _, match := range matches {
+		itemName := strings.TrimSpace(match[1])
+		// Handle cases where the item name contains ranking information
+		if strings.Contains(itemName, "\n") {
+			// Extract rank information from the item text
+			rankMatch := regexp.MustCompile(`(\w+ place)`)
+			rankSubmatch := rankMatch.FindStringSubmatch(itemName)
+			if len(rankSubmatch) > 1 {
+				rank := rankSubmatch[1]
+				// Remove rank information from the item name
+				itemName = strings.TrimSpace(strings.Replace(itemName, rank, "", 1))
+				// Create an ItemInfo struct and include rank information
+				minPrice, err := strconv.Atoi(match[2])
+				if err != nil {
+					return nil, err
+				}
+				var maxPrice int
+				if match[3] != "" {
+					maxPrice, err = strconv.Atoi(match[3])
+					if err != nil {
+						return nil, err
+					}
+				} else {
+					maxPrice = minPrice
+				}
+				item := ItemInfo{
+					Name:            itemName,
+					MinPrice:        minPrice,
+					MaxPrice:        maxPrice,
+					RankRequirement: rank,
+				}
+				items = append(items, item)
+			}
+		} else {
+			minPrice, err := strconv.Atoi(match[2])
+			if err != nil {
+				return nil, err
+			}
+			// Check if there's a specified max price
+			var maxPrice int
+			if match[3] != "" {
+				maxPrice, err = strconv.Atoi(match[3])
+				if err != nil {
+					return nil, err
+				}
+			} else {
+				// If no max price is specified, set it equal to the min price
+				maxPrice = minPrice
+			}
+			// Create an ItemInfo struct without rank information
+			item := ItemInfo{
+				Name:     itemName,
+				MinPrice: minPrice,
+				MaxPrice: maxPrice,
+			}
+			items = append(items, item)
+		}
+	}
+
+	return items, nil
+}
+
+// ExtractZone returns the zone ID of a location like "Port Bastok (H-7)".
+func ExtractZone(location string) (string, error) {
+	re := regexp.MustCompile(`([^\(]+) \([^\)]+\)`)
+	match := re.FindStringSubmatch(location)
+	if len(match) > 1 {
+		return zone.ID(strings.TrimSpace(match[1])), nil
+	}
+	return "", fmt.Errorf("unable to extract zone from location: %s", location)
+}
+
+// ExtractPosition returns the map position of a location like
+// "Port Bastok (H-7)", or "" when the location has none.
+func ExtractPosition(location string) string {
+	match := positionRe.FindStringSubmatch(location)
+	if len(match) > 1 {
+		return strings.TrimSpace(match[1])
+	}
+	return ""
+}
+
+var positionRe = regexp.MustCompile(`[^\(]+ \(([^\)]+)\)`)
+
+// GroupByZone groups merchants by their zone ID.
+func GroupByZone(merchantInfoList []MerchantInfo) map[string][]MerchantInfo {
+	zoneMerchants := make(map[string][]MerchantInfo)
+	for _, info := range merchantInfoList {
+		zoneMerchants[info.Zone] = append(zoneMerchants[info.Zone], info)
+	}
+	return zoneMerchants
+}
+
+// GoodsTable flattens merchants into one row per good.
+func GoodsTable(merchantInfoList []MerchantInfo) transform.Table {
+	table := transform.Table{Columns: []string{"Merchant", "Zone", "Item", "MinPrice", "MaxPrice", "RankRequirement"}}
+	for _, merchant := range merchantInfoList {
+		for _, item := range merchant.Items {
+			table.Rows = append(table.Rows, []string{
+				merchant.Name,
+				merchant.Zone,
+				item.Name,
+				strconv.Itoa(item.MinPrice),
+				strconv.Itoa(item.MaxPrice),
+				item.RankRequirement,
+			})
+		}
+	}
+	return table
+}
+
+// Run reads the merchant scrapes, input.json by default, from the input
+// directory and writes one <zone> file of merchants per zone into the
+// output directory.
+func Run(opts transform.Options) error {
+	if err := opts.CheckFormat(transform.Formats...); err != nil {
+		return err
+	}
+
+	inputFiles, err := opts.InputFiles("input.json")
+	if err != nil {
+		return err
+	}
+
+	now := transform.Now()
+	var merchantInfoList []MerchantInfo
+	for _, inputFile := range inputFiles {
+		// Read JSON data from the input file
+		jsonData, err := opts.ReadInput(inputFile)
+		if err != nil {
+			return err
+		}
+
+		fileMerchants, err := ExtractMerchantInfo(jsonData)
+		if err != nil {
+			return fmt.Errorf("%s: %v", inputFile, err)
+		}
+		for _, merchant := range fileMerchants {
+			merchant.Provenance.Stamp(inputFile, now)
+		}
+		opts.Logf("Extracted %d merchants from %s", len(fileMerchants), inputFile)
+		merchantInfoList = append(merchantInfoList, fileMerchants...)
+	}
+
+	// Write files for each zone
+	for zone, merchants := range GroupByZone(merchantInfoList) {
+		err = opts.Write(zone, merchants, func() transform.Table { return GoodsTable(merchants) })
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
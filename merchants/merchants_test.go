@@ -0,0 +1,67 @@
+package merchants
+
+import (
+	"ffxi/transform"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExtractGoodsAndPrices(t *testing.T) {
+	testCases := []struct {
+		input          string
+		expectedItems  []string
+		expectedPrices []int
+	}{
+		{
+			input:          "Bronze Cap 154-174 gil \nFaceguard 1334-1508 gil \nBronze Harness 235-266 gil",
+			expectedItems:  []string{"Bronze Cap", "Faceguard", "Bronze Harness"},
+			expectedPrices: []int{154, 174, 1334, 1508, 235, 266},
+		},
+		{
+			input:          "Flask of Echo Drops 800 gil",
+			expectedItems:  []string{"Flask of Echo Drops"},
+			expectedPrices: []int{800, 800},
+		},
+		// Add more test cases for different scenarios
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			items, err := ExtractGoodsAndPrices(tc.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var names []string
+			var prices []int
+			for _, item := range items {
+				names = append(names, item.Name)
+				prices = append(prices, item.MinPrice, item.MaxPrice)
+			}
+
+			// Check if the extracted items and prices match the expected values
+			if !reflect.DeepEqual(names, tc.expectedItems) {
+				t.Errorf("Expected items %v, but got %v", tc.expectedItems, names)
+			}
+
+			if !reflect.DeepEqual(prices, tc.expectedPrices) {
+				t.Errorf("Expected prices %v, but got %v", tc.expectedPrices, prices)
+			}
+		})
+	}
+}
+
+func TestRead(t *testing.T) {
+	input := `[{"merchant": "Dahjal", "type": "Item Merchant", "goodsPrice": "Ash Log 90-110 gil", "location": "Port Bastok (H-7)"}]`
+
+	merchants, err := Read(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []MerchantInfo{{SchemaVersion: transform.SchemaVersion, Name: "Dahjal", Zone: "Port_Bastok", Position: "H-7", Items: []ItemInfo{{Name: "Ash Log", MinPrice: 90, MaxPrice: 110}}, Provenance: &transform.Provenance{Row: 0}}}
+	if !reflect.DeepEqual(merchants, expected) {
+		t.Errorf("Expected %+v, but got %+v", expected, merchants)
+	}
+}
@@ -0,0 +1,38 @@
+package merchants
+
+import (
+	"encoding/json"
+	"ffxi/transform"
+	"ffxi/zone"
+)
+
+// Upgrade decodes a merchant written by any version of the merchants command
+// and brings it up to transform.SchemaVersion. Older files may spell the zone
+// the way sanitizeZoneName did, which is resolved to the canonical zone ID.
+// The returned notes name what could not be derived.
+func Upgrade(data []byte) (MerchantInfo, []string, error) {
+	var merchant MerchantInfo
+	if err := json.Unmarshal(data, &merchant); err != nil {
+		return merchant, nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return merchant, nil, err
+	}
+	if merchant.SchemaVersion >= transform.SchemaVersion {
+		return merchant, nil, nil
+	}
+
+	var notes []string
+	if merchant.Zone == "" {
+		notes = append(notes, "Zone: missing, left empty")
+	} else {
+		merchant.Zone = zone.ID(merchant.Zone)
+	}
+	if _, ok := fields["Position"]; !ok {
+		notes = append(notes, "Position: not in the file, rerun merchants on the scrape to fill it in")
+	}
+
+	merchant.SchemaVersion = transform.SchemaVersion
+	return merchant, notes, nil
+}
@@ -0,0 +1,169 @@
+// Package migrate upgrades output files written by older versions of the
+// transformers to transform.SchemaVersion.
+package migrate
+
+import (
+	"encoding/json"
+	"errors"
+	"ffxi/harvestpoints"
+	"ffxi/merchants"
+	"ffxi/mobdrops"
+	"ffxi/recipe"
+	"ffxi/schema"
+	"ffxi/transform"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Result is the outcome of migrating one file.
+type Result struct {
+	File string `json:"File"`
+	Kind string `json:"Kind"`
+	// FromVersion is the oldest record version found in the file.
+	FromVersion int  `json:"FromVersion"`
+	Records     int  `json:"Records"`
+	Migrated    bool `json:"Migrated"`
+	// Notes name the fields that could not be derived, by record path.
+	Notes []string `json:"Notes"`
+}
+
+// upgraders decode one record of each kind and upgrade it.
+var upgraders = map[string]func(data []byte) (interface{}, []string, error){
+	"recipe": func(data []byte) (interface{}, []string, error) {
+		return recipe.Upgrade(data)
+	},
+	"merchants": func(data []byte) (interface{}, []string, error) {
+		return merchants.Upgrade(data)
+	},
+	"drops": func(data []byte) (interface{}, []string, error) {
+		return mobdrops.Upgrade(data)
+	},
+	"drop-scrape": func(data []byte) (interface{}, []string, error) {
+		return mobdrops.UpgradeScrapeRow(data)
+	},
+	"harvest": func(data []byte) (interface{}, []string, error) {
+		return harvestpoints.Upgrade(data)
+	},
+}
+
+// ErrUnknownFormat is returned for files that aren't transformer outputs.
+var ErrUnknownFormat = errors.New("unknown output format")
+
+// Migrate upgrades the records of an output file. Files already at
+// transform.SchemaVersion are returned unchanged. A file holding a single
+// record stays a single record.
+func Migrate(data []byte) ([]byte, Result, error) {
+	kind, ok := schema.Detect(data)
+	if !ok {
+		return nil, Result{}, ErrUnknownFormat
+	}
+	result := Result{Kind: kind.Name, FromVersion: transform.SchemaVersion, Notes: []string{}}
+
+	var records []json.RawMessage
+	single := json.Unmarshal(data, &records) != nil
+	if single {
+		records = []json.RawMessage{data}
+	}
+	result.Records = len(records)
+
+	var upgraded []interface{}
+	for i, record := range records {
+		var version struct{ SchemaVersion int }
+		if err := json.Unmarshal(record, &version); err != nil {
+			return nil, result, err
+		}
+		if version.SchemaVersion == 0 {
+			version.SchemaVersion = 1
+		}
+		if version.SchemaVersion > transform.SchemaVersion {
+			return nil, result, fmt.Errorf("record %d is version %d, newer than this build's version %d", i, version.SchemaVersion, transform.SchemaVersion)
+		}
+		if version.SchemaVersion < result.FromVersion {
+			result.FromVersion = version.SchemaVersion
+		}
+
+		value, notes, err := upgraders[kind.Name](record)
+		if err != nil {
+			return nil, result, fmt.Errorf("record %d: %v", i, err)
+		}
+		path := fmt.Sprintf("$[%d]", i)
+		if single {
+			path = "$"
+		}
+		for _, note := range notes {
+			result.Notes = append(result.Notes, path+" "+note)
+		}
+		upgraded = append(upgraded, value)
+	}
+
+	if result.FromVersion == transform.SchemaVersion {
+		return data, result, nil
+	}
+	result.Migrated = true
+
+	var v interface{} = upgraded
+	if single {
+		v = upgraded[0]
+	}
+	migrated, err := json.MarshalIndent(v, "", "  ")
+	return migrated, result, err
+}
+
+// Path migrates an output file, or every .json file under a directory. The
+// upgraded files are written to the same relative path under outDir, or in
+// place when outDir is empty. With dryRun nothing is written. Files that
+// aren't transformer outputs are reported and left alone.
+func Path(root, outDir string, dryRun bool) ([]Result, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, err
+	}
+	base := root
+	if !info.IsDir() {
+		base = filepath.Dir(root)
+	}
+
+	var results []Result
+	err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || strings.ToLower(filepath.Ext(path)) != ".json" {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		migrated, result, err := Migrate(data)
+		result.File = path
+		if err != nil {
+			result.Notes = []string{"skipped: " + err.Error()}
+			results = append(results, result)
+			return nil
+		}
+		results = append(results, result)
+
+		target := path
+		if outDir != "" {
+			rel, err := filepath.Rel(base, path)
+			if err != nil {
+				return err
+			}
+			target = filepath.Join(outDir, rel)
+		} else if !result.Migrated {
+			return nil
+		}
+		if dryRun {
+			return nil
+		}
+		if err := os.MkdirAll(filepath.Dir(target), os.ModePerm); err != nil {
+			return err
+		}
+		return os.WriteFile(target, migrated, 0644)
+	})
+	return results, err
+}
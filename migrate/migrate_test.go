@@ -0,0 +1,165 @@
+package migrate
+
+import (
+	"encoding/json"
+	"ffxi/harvestpoints"
+	"ffxi/mobdrops"
+	"ffxi/recipe"
+	"ffxi/schema"
+	"ffxi/transform"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMigrate(t *testing.T) {
+	testCases := []struct {
+		name          string
+		input         string
+		expectedNotes []string
+	}{
+		{
+			name:  "sample.json recipe",
+			input: `[{"Crystal": "Wind Crystal", "RequiredItems": [{"Name": "Ash Log", "Count": 1}], "SkillLevels": {"Woodworking": 8}, "Result": "Ash Lumber", "Name": "Ash Lumber-From-1Ash Log"}]`,
+			expectedNotes: []string{
+				"$[0] AllPossibleResults: HQ results are not in the file, only the NQ result was derived",
+				"$[0] RequiredTools: not in the file, left empty",
+			},
+		},
+		{
+			name:          "merchant with sanitized zone",
+			input:         `[{"Name": "Dahjal", "Zone": "Port_Bastok", "Items": [{"Name": "Ash Log", "MinPrice": 90, "MaxPrice": 110, "RankRequirement": ""}]}]`,
+			expectedNotes: []string{"$[0] Position: not in the file, rerun merchants on the scrape to fill it in"},
+		},
+		{
+			name:  "mob with defaulted rates",
+			input: `[{"Name": "Bogy", "LevelRange": {"Min": 0, "Max": 0}, "ZoneName": "Valkurm Dunes", "ItemDrops": [{"Name": "ash log", "Percent": 100, "AmountDropped": 0, "AmountDefeated": 0}, {"Name": "bloody robe", "Percent": 39.2, "AmountDropped": 652, "AmountDefeated": 1665}]}]`,
+			expectedNotes: []string{
+				"$[0] ItemDrops[0] ash log: 100% without counts was the old default for a missing rate, now unknown",
+				"$[0] ItemDrops[1] bloody robe: Source not in the file, assumed scrape",
+			},
+		},
+		{
+			name:          "hand-made harvest file",
+			input:         `[{"Name": "Harvesting Point", "LevelRange": null, "ZoneName": "Giddeus", "ItemDrops": [], "ItemDropInfos": [{"Name": "Grain Seeds", "FriendlyName": "Grain_Seeds", "TotalKnownDrops": 2}], "TotalKnownDefeated": 100}]`,
+			expectedNotes: []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			migrated, result, err := Migrate([]byte(tc.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !result.Migrated || result.FromVersion != 1 {
+				t.Errorf("Expected a migration from version 1, but got %+v", result)
+			}
+			if !reflect.DeepEqual(result.Notes, tc.expectedNotes) {
+				t.Errorf("Expected notes %q, but got %q", tc.expectedNotes, result.Notes)
+			}
+
+			// The migrated file must be valid against the current schema
+			kind, _ := schema.Lookup(result.Kind)
+			issues, err := kind.Schema().Validate(migrated)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(issues) > 0 {
+				t.Errorf("Expected a valid file, but got %+v", issues)
+			}
+
+			// Migrating again changes nothing
+			again, result, err := Migrate(migrated)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Migrated || string(again) != string(migrated) {
+				t.Errorf("Expected the migrated file to be current")
+			}
+		})
+	}
+}
+
+func TestMigrateDerivedFields(t *testing.T) {
+	migrated, _, err := Migrate([]byte(`{"Crystal": "Wind Crystal", "RequiredItems": [{"Name": "Ash Log", "Count": 1}], "SkillLevels": {"Woodworking": 8}, "Result": "Ash Lumber", "Name": "Ash Lumber x2-From-1Ash Log"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var r recipe.CraftingRecipe
+	if err := json.Unmarshal(migrated, &r); err != nil {
+		t.Fatalf("Expected a single recipe to stay single: %v", err)
+	}
+	expected := recipe.CraftingRecipe{
+		SchemaVersion:      transform.SchemaVersion,
+		Crystal:            "Wind Crystal",
+		RequiredItems:      []recipe.Item{{Name: "Ash Log", Count: 1}},
+		SkillLevels:        map[string]int{"Woodworking": 8},
+		Result:             "Ash Lumber",
+		Name:               "Woodworking-8-Ash Lumber x2-From-1-Ash Log",
+		MainCraft:          "Woodworking",
+		AllPossibleResults: []recipe.ResultsIncludingHighQuality{{Name: "Ash Lumber", Count: 2}},
+	}
+	if !reflect.DeepEqual(r, expected) {
+		t.Errorf("Expected %+v, but got %+v", expected, r)
+	}
+
+	migrated, _, err = Migrate([]byte(`[{"Name": "Bogy", "LevelRange": {"Min": 0, "Max": 0}, "ZoneName": "Valkurm Dunes", "ItemDrops": [{"Name": "ash log", "Percent": 100, "AmountDropped": 0, "AmountDefeated": 0}]}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var mobs []mobdrops.MobInfo
+	if err := json.Unmarshal(migrated, &mobs); err != nil {
+		t.Fatal(err)
+	}
+	if mobs[0].LevelRange != nil || mobs[0].ZoneName != "Valkurm_Dunes" || mobs[0].ItemDrops[0].Percent != nil {
+		t.Errorf("Expected unknown levels and rates and a canonical zone, but got %+v", mobs[0])
+	}
+
+	migrated, _, err = Migrate([]byte(`[{"Name": "Logging Point", "ZoneName": "Giddeus", "ItemDropInfos": []}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var points []harvestpoints.HarvestPoint
+	if err := json.Unmarshal(migrated, &points); err != nil {
+		t.Fatal(err)
+	}
+	if points[0].PointType != harvestpoints.Logging || points[0].RequiredTool != "Hatchet" {
+		t.Errorf("Expected a logging point with a hatchet, but got %+v", points[0])
+	}
+}
+
+func TestPath(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"sample.json": `[{"Crystal": "Wind", "RequiredItems": [], "SkillLevels": {"Woodworking": 8}, "Result": "Ash Lumber", "Name": "Ash Lumber-From-"}]`,
+		"notes.json":  `{"Todo": "more zones"}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	outDir := filepath.Join(t.TempDir(), "migrated")
+	results, err := Path(dir, outDir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, but got %+v", results)
+	}
+	if _, err := os.Stat(filepath.Join(outDir, "sample.json")); err != nil {
+		t.Errorf("Expected the migrated sample.json: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(outDir, "notes.json")); !os.IsNotExist(err) {
+		t.Errorf("Expected notes.json to be skipped")
+	}
+
+	// The input is left alone when writing elsewhere
+	original, _ := os.ReadFile(filepath.Join(dir, "sample.json"))
+	if string(original) != files["sample.json"] {
+		t.Errorf("Expected the input to be unchanged")
+	}
+}
@@ -0,0 +1,148 @@
+package mobdrops
+
+import (
+	"ffxi/transform"
+	"ffxi/zone"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Drop rates of one batch that are this many standard errors away from the
+// rest of the batches are flagged as a conflict.
+const conflictZ = 1.96
+
+// ItemInfoBatch is one numbered scrape export, e.g. all_mobs_nineth.json.
+type ItemInfoBatch struct {
+	Name  string
+	Items []ItemInfo
+}
+
+// BatchCount records what a single scrape batch contributed to a merged row.
+type BatchCount struct {
+	Batch          string `json:"Batch"`
+	Chance         string `json:"Chance"`
+	AmountDropped  int    `json:"AmountDropped"`
+	AmountDefeated int    `json:"AmountDefeated"`
+	// Provenance is the scrape row of the batch.
+	Provenance *transform.Provenance `json:"Provenance,omitempty"`
+}
+
+// MergeItemInfo combines rows for the same NPC, item and zone across batches
+// by summing their drop and kill counts. Rows without counts only fill in a
+// chance when no batch has counts for that row.
+func MergeItemInfo(batches []ItemInfoBatch) []ItemInfo {
+	var merged []ItemInfo
+	indexByKey := make(map[string]int)
+
+	for _, batch := range batches {
+		for _, info := range batch.Items {
+			key := strings.ToLower(info.NPC + "|" + info.ItemName + "|" + zone.ID(info.Zone))
+			count := BatchCount{Batch: batch.Name, Chance: info.Chance, Provenance: info.Provenance}
+			dropped, defeated, err := ParseCount(info.Count)
+			if err == nil {
+				count.AmountDropped = dropped
+				count.AmountDefeated = defeated
+			}
+
+			i, ok := indexByKey[key]
+			if !ok {
+				info.Batches = nil
+				indexByKey[key] = len(merged)
+				merged = append(merged, info)
+				i = len(merged) - 1
+			}
+			merged[i].Batches = append(merged[i].Batches, count)
+		}
+	}
+
+	for i := range merged {
+		applyBatchCounts(&merged[i])
+		if len(merged[i].Batches) > 0 {
+			merged[i].Provenance = merged[i].Batches[0].Provenance
+		}
+		merged[i].SchemaVersion = transform.SchemaVersion
+	}
+
+	return merged
+}
+
+// applyBatchCounts recomputes Count and Chance of a merged row from its batches
+// and flags batches whose rates differ from the rest. A row counted by a
+// single batch keeps the chance of that batch as scraped.
+func applyBatchCounts(info *ItemInfo) {
+	var dropped, defeated int
+	for _, count := range info.Batches {
+		dropped += count.AmountDropped
+		defeated += count.AmountDefeated
+	}
+
+	if defeated == 0 {
+		// No batch has counts, keep the first chance that parses
+		for _, count := range info.Batches {
+			if _, err := ParsePercent(count.Chance); err == nil {
+				info.Chance = count.Chance
+				info.Count = ""
+				info.Batches = []BatchCount{count}
+				return
+			}
+		}
+		return
+	}
+
+	var counted []BatchCount
+	for _, count := range info.Batches {
+		if count.AmountDefeated > 0 {
+			counted = append(counted, count)
+		}
+	}
+	info.Batches = counted
+	info.Count = fmt.Sprintf("%d out of %d", dropped, defeated)
+	info.Chance = formatPercent(float64(dropped) / float64(defeated) * 100)
+	if len(counted) == 1 {
+		if _, err := ParsePercent(counted[0].Chance); err == nil {
+			info.Chance = counted[0].Chance
+		}
+	}
+	info.Conflict = false
+
+	for _, count := range counted {
+		restDropped := dropped - count.AmountDropped
+		restDefeated := defeated - count.AmountDefeated
+		if restDefeated == 0 {
+			continue
+		}
+		if ratesDiffer(count.AmountDropped, count.AmountDefeated, restDropped, restDefeated) {
+			info.Conflict = true
+			log.Printf("Drop rate of %s from %s in %s differs in batch %s: %s vs %d out of %d in other batches",
+				info.ItemName, info.NPC, info.Zone, count.Batch, count.Chance, restDropped, restDefeated)
+		}
+	}
+}
+
+// ratesDiffer runs a two-proportion z-test on two drop samples.
+func ratesDiffer(dropped1, defeated1, dropped2, defeated2 int) bool {
+	n1, n2 := float64(defeated1), float64(defeated2)
+	p1, p2 := float64(dropped1)/n1, float64(dropped2)/n2
+	pooled := float64(dropped1+dropped2) / (n1 + n2)
+	standardError := math.Sqrt(pooled * (1 - pooled) * (1/n1 + 1/n2))
+	if standardError == 0 {
+		return p1 != p2
+	}
+	return math.Abs(p1-p2)/standardError > conflictZ
+}
+
+func formatPercent(percent float64) string {
+	return strconv.FormatFloat(math.Round(percent*100)/100, 'f', -1, 64) + "%"
+}
+
+// BatchNames lists the batches a merged row was built from.
+func BatchNames(info ItemInfo) []string {
+	var names []string
+	for _, count := range info.Batches {
+		names = append(names, count.Batch)
+	}
+	return names
+}
@@ -0,0 +1,69 @@
+package mobdrops
+
+import (
+	"encoding/json"
+	"ffxi/transform"
+	"ffxi/zone"
+	"fmt"
+)
+
+// Upgrade decodes a mob written by any version of the drops command and
+// brings it up to transform.SchemaVersion. Version 1 files wrote unknown
+// levels as 0-0 and unknown drop rates as 100%, both become null. The
+// returned notes name what could not be derived.
+func Upgrade(data []byte) (MobInfo, []string, error) {
+	var mob MobInfo
+	if err := json.Unmarshal(data, &mob); err != nil {
+		return mob, nil, err
+	}
+	var raw struct {
+		ItemDrops []map[string]json.RawMessage
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return mob, nil, err
+	}
+	if mob.SchemaVersion >= transform.SchemaVersion {
+		return mob, nil, nil
+	}
+
+	var notes []string
+	if mob.LevelRange != nil && mob.LevelRange.Min == 0 && mob.LevelRange.Max == 0 {
+		mob.LevelRange = nil
+	}
+	mob.ZoneName = zone.ID(mob.ZoneName)
+
+	for i := range mob.ItemDrops {
+		drop := &mob.ItemDrops[i]
+		if _, ok := raw.ItemDrops[i]["Source"]; ok {
+			continue
+		}
+		switch {
+		case drop.Percent == nil:
+			drop.Source = SourceUnknown
+		case *drop.Percent == 100 && drop.AmountDefeated == 0:
+			drop.Percent = nil
+			drop.Source = SourceUnknown
+			notes = append(notes, fmt.Sprintf("ItemDrops[%d] %s: 100%% without counts was the old default for a missing rate, now unknown", i, drop.Name))
+		default:
+			drop.Source = SourceScrape
+			notes = append(notes, fmt.Sprintf("ItemDrops[%d] %s: Source not in the file, assumed scrape", i, drop.Name))
+		}
+	}
+
+	mob.SchemaVersion = transform.SchemaVersion
+	return mob, notes, nil
+}
+
+// UpgradeScrapeRow decodes a merged scrape row and brings it up to
+// transform.SchemaVersion. Rows have only gained optional fields, so there is
+// nothing to derive.
+func UpgradeScrapeRow(data []byte) (ItemInfo, []string, error) {
+	var info ItemInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		return info, nil, err
+	}
+	if info.SchemaVersion < transform.SchemaVersion {
+		info.SchemaVersion = transform.SchemaVersion
+	}
+	return info, nil, nil
+}
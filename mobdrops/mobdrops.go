@@ -0,0 +1,430 @@
+// Package mobdrops merges scraped mob drop rates and fills them into the drop
+// lists of zone mob files.
+package mobdrops
+
+import (
+	"bytes"
+	"encoding/json"
+	"ffxi/transform"
+	"ffxi/zone"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ItemInfo is one scraped drop row: how often NPC dropped ItemName in Zone.
+type ItemInfo struct {
+	// SchemaVersion is only set on merged rows, scrape rows have none.
+	SchemaVersion int          `json:"SchemaVersion,omitempty"`
+	ItemName      string       `json:"ItemName"`
+	NPC           string       `json:"NPC"`
+	NPCURL        string       `json:"NPC_URL,omitempty"`
+	Zone          string       `json:"Zone"`
+	Count         string       `json:"Count"`
+	Chance        string       `json:"Chance"`
+	PageURL       string       `json:"Page_URL"`
+	Batches       []BatchCount `json:"Batches,omitempty"`
+	Conflict      bool         `json:"Conflict,omitempty"`
+	// Provenance is the scrape row, of the first batch for merged rows.
+	Provenance *transform.Provenance `json:"Provenance,omitempty"`
+}
+
+// Drop rate sources recorded in ItemDrop.Source.
+const (
+	SourceScrape    = "scrape"     // scrape row for this mob in this zone
+	SourceOtherZone = "other_zone" // scrape row for the same mob in another zone
+	SourceUnknown   = "unknown"    // no usable scrape row, Percent is null
+)
+
+// ItemDrop is a single drop of a mob. Percent is nil when the drop rate is
+// unknown so a missing rate is never mistaken for a guaranteed drop.
+type ItemDrop struct {
+	Name           string   `json:"Name"`
+	Percent        *float64 `json:"Percent"`
+	Source         string   `json:"Source"`
+	SourceZone     string   `json:"SourceZone,omitempty"`
+	AmountDropped  int      `json:"AmountDropped"`
+	AmountDefeated int      `json:"AmountDefeated"`
+	// Batches are the scrape batches the rate was merged from.
+	Batches      []string `json:"Batches,omitempty"`
+	RateConflict bool     `json:"RateConflict,omitempty"`
+	// Provenance is the scrape row the rate was taken from.
+	Provenance *transform.Provenance `json:"Provenance,omitempty"`
+}
+
+// UnmarshalJSON accepts a bare item name as a drop, as hand-made mob files
+// list drops by name only.
+func (d *ItemDrop) UnmarshalJSON(data []byte) error {
+	var name string
+	if json.Unmarshal(data, &name) == nil {
+		*d = ItemDrop{Name: name}
+		return nil
+	}
+
+	// itemDrop has no methods, so decoding it doesn't recurse
+	type itemDrop ItemDrop
+	return json.Unmarshal(data, (*itemDrop)(d))
+}
+
+// MobInfo is a mob of a zone mob file with its drops. LevelRange is nil when
+// the levels are unknown.
+type MobInfo struct {
+	SchemaVersion int         `json:"SchemaVersion"`
+	Name          string      `json:"Name"`
+	LevelRange    *LevelRange `json:"LevelRange"`
+	ZoneName      string      `json:"ZoneName"`
+	ItemDrops     []ItemDrop  `json:"ItemDrops"`
+}
+
+// LevelRange is the level spread of a mob.
+type LevelRange struct {
+	Min int `json:"Min"`
+	Max int `json:"Max"`
+}
+
+// DefaultZones are the zone files Run updates when no zones are given.
+var DefaultZones = []string{
+	"Valkurm_Dunes",
+	//"Jugner_Forest",
+} // Add more zones as needed
+
+// Run merges every all_mobs_*.json scrape batch in the input directory and
+// writes merged_drops plus <zone>_output for each zone's
+// <zone>.json mob file into the output directory.
+func Run(opts transform.Options, zones []string) error {
+	if err := opts.CheckFormat(transform.Formats...); err != nil {
+		return err
+	}
+	if len(zones) == 0 {
+		zones = DefaultZones
+	}
+
+	// Load item drop info from every numbered scrape batch
+	batchFiles, err := opts.InputFiles("all_mobs_*.json")
+	if err != nil {
+		return err
+	}
+
+	now := transform.Now()
+	var batches []ItemInfoBatch
+	for _, batchFile := range batchFiles {
+		opts.Logf("Loading drop batch %s", batchFile)
+		items, err := loadItemInfo(opts, batchFile)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			item.Provenance.Stamp(batchFile, now)
+		}
+		batches = append(batches, ItemInfoBatch{Name: filepath.Base(batchFile), Items: items})
+	}
+
+	itemInfo := MergeItemInfo(batches)
+	err = opts.Write("merged_drops", itemInfo, func() transform.Table { return ScrapeTable(itemInfo) })
+	if err != nil {
+		return err
+	}
+
+	// Load and update mob info for each zone
+	for _, zoneName := range zones {
+		opts.Logf("Updating drop chances of %s", zoneName)
+		mobInfo, err := loadMobInfo(opts, filepath.Join(opts.InputDir, zoneName+".json"))
+		if err != nil {
+			return err
+		}
+		for i := range mobInfo {
+			mobInfo[i].ZoneName = zone.ID(mobInfo[i].ZoneName)
+		}
+
+		UpdateDropChances(mobInfo, itemInfo)
+
+		err = opts.Write(zoneName+"_output", mobInfo, func() transform.Table { return DropTable(mobInfo) })
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DropTable flattens mobs into one row per drop.
+func DropTable(mobInfo []MobInfo) transform.Table {
+	table := transform.Table{Columns: []string{"Mob", "Zone", "MinLevel", "MaxLevel", "Item", "Percent", "Source", "SourceZone", "AmountDropped", "AmountDefeated", "RateConflict"}}
+	for _, mob := range mobInfo {
+		var minLevel, maxLevel string
+		if mob.LevelRange != nil {
+			minLevel, maxLevel = strconv.Itoa(mob.LevelRange.Min), strconv.Itoa(mob.LevelRange.Max)
+		}
+		for _, drop := range mob.ItemDrops {
+			table.Rows = append(table.Rows, []string{
+				mob.Name,
+				mob.ZoneName,
+				minLevel,
+				maxLevel,
+				drop.Name,
+				transform.FormatFloat(drop.Percent),
+				drop.Source,
+				drop.SourceZone,
+				strconv.Itoa(drop.AmountDropped),
+				strconv.Itoa(drop.AmountDefeated),
+				strconv.FormatBool(drop.RateConflict),
+			})
+		}
+	}
+	return table
+}
+
+// ScrapeTable flattens merged scrape rows into one row each.
+func ScrapeTable(itemInfo []ItemInfo) transform.Table {
+	table := transform.Table{Columns: []string{"Item", "NPC", "Zone", "Count", "Chance", "Batches", "Conflict"}}
+	for _, info := range itemInfo {
+		table.Rows = append(table.Rows, []string{
+			info.ItemName,
+			info.NPC,
+			info.Zone,
+			info.Count,
+			info.Chance,
+			strings.Join(BatchNames(info), ";"),
+			strconv.FormatBool(info.Conflict),
+		})
+	}
+	return table
+}
+
+func loadItemInfo(opts transform.Options, filename string) ([]ItemInfo, error) {
+	fileContent, err := readInput(opts, filename)
+	if err != nil {
+		return nil, err
+	}
+
+	itemInfo, err := ReadItemInfo(bytes.NewReader(fileContent))
+	if err != nil {
+		log.Printf("Error unmarshalling JSON from file %s: %v", filename, err)
+		return nil, err
+	}
+	return itemInfo, nil
+}
+
+func loadMobInfo(opts transform.Options, filename string) ([]MobInfo, error) {
+	fileContent, err := readInput(opts, filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return ReadMobInfo(bytes.NewReader(fileContent))
+}
+
+// readInput reads an input file, converting Python style dicts to JSON
+// first when a column profile has to be applied.
+func readInput(opts transform.Options, filename string) ([]byte, error) {
+	if len(opts.Profile) == 0 {
+		return ioutil.ReadFile(filename)
+	}
+
+	fileContent, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	if !json.Valid(fileContent) {
+		fileContent = []byte(singleToDoubleQuotes(string(fileContent)))
+	}
+	fileContent, err = opts.Profile.Apply(fileContent)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+	return fileContent, nil
+}
+
+// ReadItemInfo reads a scrape batch, a JSON list of ItemInfo rows, recording
+// the row and page links of each in its Provenance. Batches exported as
+// Python style dicts with single quotes are accepted too.
+func ReadItemInfo(r io.Reader) ([]ItemInfo, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Retry with single quotes swapped for double quotes
+	var itemInfo []ItemInfo
+	err = json.Unmarshal(content, &itemInfo)
+	if err != nil {
+		err = json.Unmarshal([]byte(singleToDoubleQuotes(string(content))), &itemInfo)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range itemInfo {
+		itemInfo[i].Provenance = &transform.Provenance{Row: i, SourceURL: itemInfo[i].PageURL, NPCURL: itemInfo[i].NPCURL}
+	}
+	return itemInfo, nil
+}
+
+// singleToDoubleQuotes replaces single quotes not preceded by a backslash with
+// double quotes and unescapes the escaped ones.
+func singleToDoubleQuotes(content string) string {
+	var sb strings.Builder
+	escaped := false
+	for _, r := range content {
+		switch {
+		case escaped && r == '\'':
+			sb.WriteRune(r)
+		case escaped:
+			sb.WriteRune('\\')
+			sb.WriteRune(r)
+		case r == '\\':
+			escaped = true
+			continue
+		case r == '\'':
+			sb.WriteRune('"')
+		default:
+			sb.WriteRune(r)
+		}
+		escaped = false
+	}
+	return sb.String()
+}
+
+// ReadMobInfo reads a zone mob file, a JSON list of MobInfo. Like scrape
+// batches, mob files exported as Python style dicts are accepted too.
+func ReadMobInfo(r io.Reader) ([]MobInfo, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Retry with single quotes swapped for double quotes
+	var mobInfo []MobInfo
+	err = json.Unmarshal(content, &mobInfo)
+	if err != nil {
+		err = json.Unmarshal([]byte(singleToDoubleQuotes(string(content))), &mobInfo)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return mobInfo, nil
+}
+
+// UpdateDropChances fills in the drop rate of every mob drop from the scrape
+// rows, preferring the row for the mob's own zone over the same mob in
+// another zone. Drops without a usable row get a nil Percent and
+// SourceUnknown.
+func UpdateDropChances(mobInfo []MobInfo, itemInfo []ItemInfo) {
+	for i, mob := range mobInfo {
+		var updatedItemDrops []ItemDrop
+
+		for _, item := range mob.ItemDrops {
+			drop := ItemDrop{
+				Name:           item.Name,
+				Source:         SourceUnknown,
+				AmountDropped:  item.AmountDropped,
+				AmountDefeated: item.AmountDefeated,
+			}
+
+			// Prefer the scrape row for this zone, then the same mob elsewhere
+			if info, ok := findItemInfo(itemInfo, item.Name, mob.Name, mob.ZoneName); ok {
+				drop.Percent = percentOrNil(item.Name, info.Chance)
+				if drop.Percent != nil {
+					drop.Source = SourceScrape
+					applyScrapeCounts(&drop, info)
+				}
+			}
+			if drop.Percent == nil {
+				if info, ok := findItemInfoOtherZone(itemInfo, item.Name, mob.Name, mob.ZoneName); ok {
+					drop.Percent = percentOrNil(item.Name, info.Chance)
+					if drop.Percent != nil {
+						drop.Source = SourceOtherZone
+						drop.SourceZone = zone.ID(info.Zone)
+						applyScrapeCounts(&drop, info)
+					}
+				}
+			}
+
+			updatedItemDrops = append(updatedItemDrops, drop)
+		}
+
+		// Update the mob's ItemDrops field
+		mobInfo[i].ItemDrops = updatedItemDrops
+		mobInfo[i].SchemaVersion = transform.SchemaVersion
+	}
+}
+
+// findItemInfo returns the scrape row for an item dropped by a mob in a zone.
+func findItemInfo(itemInfo []ItemInfo, itemName, npc, zoneName string) (ItemInfo, bool) {
+	for _, info := range itemInfo {
+		if strings.EqualFold(itemName, info.ItemName) && strings.EqualFold(npc, info.NPC) && zone.Same(zoneName, info.Zone) {
+			return info, true
+		}
+	}
+	return ItemInfo{}, false
+}
+
+// findItemInfoOtherZone returns the scrape row for the same item and mob in
+// any other zone. When several zones match, the one with the most kills wins.
+func findItemInfoOtherZone(itemInfo []ItemInfo, itemName, npc, zoneName string) (ItemInfo, bool) {
+	var best ItemInfo
+	bestDefeated := -1
+	for _, info := range itemInfo {
+		if !strings.EqualFold(itemName, info.ItemName) || !strings.EqualFold(npc, info.NPC) || zone.Same(zoneName, info.Zone) {
+			continue
+		}
+		_, defeated, err := ParseCount(info.Count)
+		if err != nil {
+			defeated = 0
+		}
+		if defeated > bestDefeated {
+			best = info
+			bestDefeated = defeated
+		}
+	}
+	return best, bestDefeated >= 0
+}
+
+// applyScrapeCounts copies the merged counts, batches and provenance of a
+// scrape row.
+func applyScrapeCounts(drop *ItemDrop, info ItemInfo) {
+	if dropped, defeated, err := ParseCount(info.Count); err == nil {
+		drop.AmountDropped = dropped
+		drop.AmountDefeated = defeated
+	}
+	drop.Batches = BatchNames(info)
+	drop.RateConflict = info.Conflict
+	drop.Provenance = info.Provenance
+}
+
+// percentOrNil parses a chance string, returning nil if it can't be parsed.
+func percentOrNil(itemName, chance string) *float64 {
+	percent, err := ParsePercent(chance)
+	if err != nil {
+		log.Printf("Error parsing percent for item %s: %v", itemName, err)
+		return nil
+	}
+	return &percent
+}
+
+// ParsePercent parses a chance string like "39.2%".
+func ParsePercent(percentStr string) (float64, error) {
+	var percent float64
+	_, err := fmt.Sscanf(percentStr, "%f%%", &percent)
+	if err != nil {
+		return 0, err
+	}
+
+	return percent, nil
+}
+
+// ParseCount parses a count string like "26 out of 66" into drops and kills.
+func ParseCount(countStr string) (int, int, error) {
+	var dropped, defeated int
+	_, err := fmt.Sscanf(strings.TrimSpace(countStr), "%d out of %d", &dropped, &defeated)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return dropped, defeated, nil
+}
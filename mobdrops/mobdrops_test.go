@@ -0,0 +1,214 @@
+package mobdrops
+
+import (
+	"encoding/json"
+	"ffxi/transform"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUpdateDropChances(t *testing.T) {
+	itemInfo := []ItemInfo{
+		{ItemName: "Bloody Robe", NPC: "Bogy", Zone: "Valkurm Dunes", Count: "652 out of 1665", Chance: "39.2%"},
+		{ItemName: "Bat Wing", NPC: "Sand Bats", Zone: "Jugner_Forest", Count: "3 out of 10", Chance: "30%"},
+		{ItemName: "Bat Wing", NPC: "Sand Bats", Zone: "Pashhow Marshlands", Count: "40 out of 100", Chance: "40%"},
+		{ItemName: "Wind Crystal", NPC: "Sand Bats", Zone: "Valkurm_Dunes", Count: "", Chance: "n/a"},
+	}
+	mobInfo := []MobInfo{
+		{Name: "Bogy", ZoneName: "Valkurm_Dunes", ItemDrops: []ItemDrop{{Name: "Bloody Robe"}}},
+		{Name: "Sand Bats", ZoneName: "Valkurm_Dunes", ItemDrops: []ItemDrop{{Name: "Bat Wing"}, {Name: "Wind Crystal"}, {Name: "Sand Bat Fang"}}},
+	}
+
+	UpdateDropChances(mobInfo, itemInfo)
+
+	testCases := []struct {
+		drop       ItemDrop
+		percent    *float64
+		source     string
+		sourceZone string
+	}{
+		{mobInfo[0].ItemDrops[0], floatPtr(39.2), SourceScrape, ""},
+		{mobInfo[1].ItemDrops[0], floatPtr(40), SourceOtherZone, "Pashhow_Marshlands"},
+		{mobInfo[1].ItemDrops[1], nil, SourceUnknown, ""},
+		{mobInfo[1].ItemDrops[2], nil, SourceUnknown, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.drop.Name, func(t *testing.T) {
+			if (tc.percent == nil) != (tc.drop.Percent == nil) || (tc.percent != nil && *tc.percent != *tc.drop.Percent) {
+				t.Errorf("Expected percent %v, but got %v", tc.percent, tc.drop.Percent)
+			}
+			if tc.drop.Source != tc.source {
+				t.Errorf("Expected source %s, but got %s", tc.source, tc.drop.Source)
+			}
+			if tc.drop.SourceZone != tc.sourceZone {
+				t.Errorf("Expected source zone %s, but got %s", tc.sourceZone, tc.drop.SourceZone)
+			}
+		})
+	}
+}
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
+
+func TestMergeItemInfo(t *testing.T) {
+	batches := []ItemInfoBatch{
+		{Name: "all_mobs_first.json", Items: []ItemInfo{
+			{ItemName: "Bloody Robe", NPC: "Bogy", Zone: "Valkurm Dunes", Count: "40 out of 100", Chance: "40%"},
+			{ItemName: "Bat Wing", NPC: "Sand Bats", Zone: "Valkurm Dunes", Count: "10 out of 100", Chance: "10%"},
+			{ItemName: "Wind Crystal", NPC: "Sand Bats", Zone: "Valkurm Dunes", Chance: "5%"},
+			{ItemName: "Damselfly Worm", NPC: "Damselfly", Zone: "Valkurm Dunes", Count: "41 out of 104", Chance: "39.4%"},
+		}},
+		{Name: "all_mobs_second.json", Items: []ItemInfo{
+			{ItemName: "bloody robe", NPC: "Bogy", Zone: "Valkurm_Dunes", Count: "20 out of 60", Chance: "33.3%"},
+			{ItemName: "Bat Wing", NPC: "Sand Bats", Zone: "Valkurm Dunes", Count: "60 out of 100", Chance: "60%"},
+			{ItemName: "Wind Crystal", NPC: "Sand Bats", Zone: "Valkurm Dunes", Chance: "7%"},
+		}},
+	}
+
+	merged := MergeItemInfo(batches)
+
+	testCases := []struct {
+		count    string
+		chance   string
+		conflict bool
+		batches  []string
+	}{
+		{"60 out of 160", "37.5%", false, []string{"all_mobs_first.json", "all_mobs_second.json"}},
+		{"70 out of 200", "35%", true, []string{"all_mobs_first.json", "all_mobs_second.json"}},
+		{"", "5%", false, []string{"all_mobs_first.json"}},
+		// One batch has counts, its chance is kept as scraped
+		{"41 out of 104", "39.4%", false, []string{"all_mobs_first.json"}},
+	}
+
+	if len(merged) != len(testCases) {
+		t.Fatalf("Expected %d merged rows, but got %d", len(testCases), len(merged))
+	}
+	for i, tc := range testCases {
+		t.Run(merged[i].ItemName, func(t *testing.T) {
+			if merged[i].Count != tc.count || merged[i].Chance != tc.chance {
+				t.Errorf("Expected %s (%s), but got %s (%s)", tc.count, tc.chance, merged[i].Count, merged[i].Chance)
+			}
+			if merged[i].Conflict != tc.conflict {
+				t.Errorf("Expected conflict %v, but got %v", tc.conflict, merged[i].Conflict)
+			}
+			if !reflect.DeepEqual(BatchNames(merged[i]), tc.batches) {
+				t.Errorf("Expected batches %v, but got %v", tc.batches, BatchNames(merged[i]))
+			}
+		})
+	}
+}
+
+func TestReadItemInfo(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input string
+	}{
+		{"json", `[{"ItemName": "Bat Wing", "NPC_URL": "http://www.ffxidb.com/zones/103/sand-bats", "NPC": "Sand Bats", "Zone": "Valkurm_Dunes", "Count": "3 out of 10", "Chance": "30%", "Page_URL": "http://www.ffxidb.com/items/922"}]`},
+		{"python dicts", `[{'ItemName': 'Bat Wing', 'NPC_URL': 'http://www.ffxidb.com/zones/103/sand-bats', 'NPC': 'Sand Bats', 'Zone': 'Valkurm_Dunes', 'Count': '3 out of 10', 'Chance': '30%', 'Page_URL': 'http://www.ffxidb.com/items/922'}]`},
+	}
+
+	expected := []ItemInfo{{
+		ItemName:   "Bat Wing",
+		NPC:        "Sand Bats",
+		NPCURL:     "http://www.ffxidb.com/zones/103/sand-bats",
+		Zone:       "Valkurm_Dunes",
+		Count:      "3 out of 10",
+		Chance:     "30%",
+		PageURL:    "http://www.ffxidb.com/items/922",
+		Provenance: &transform.Provenance{Row: 0, SourceURL: "http://www.ffxidb.com/items/922", NPCURL: "http://www.ffxidb.com/zones/103/sand-bats"},
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			itemInfo, err := ReadItemInfo(strings.NewReader(tc.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(itemInfo, expected) {
+				t.Errorf("Expected %+v, but got %+v", expected, itemInfo)
+			}
+		})
+	}
+}
+
+func TestDropProvenance(t *testing.T) {
+	itemInfo, err := ReadItemInfo(strings.NewReader(`[
+		{"ItemName": "Bloody Robe", "NPC": "Bogy", "Zone": "Jugner Forest", "Count": "26 out of 66", "Chance": "39.4%"},
+		{"ItemName": "Bloody Robe", "NPC": "Bogy", "Zone": "Valkurm Dunes", "Count": "652 out of 1665", "Chance": "39.2%", "Page_URL": "http://www.ffxidb.com/items/540"}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for _, info := range itemInfo {
+		info.Provenance.Stamp("exports/all_mobs_nineth.json", at)
+	}
+
+	merged := MergeItemInfo([]ItemInfoBatch{{Name: "all_mobs_nineth.json", Items: itemInfo}})
+	mobInfo := []MobInfo{{Name: "Bogy", ZoneName: "Valkurm_Dunes", ItemDrops: []ItemDrop{{Name: "Bloody Robe"}}}}
+	UpdateDropChances(mobInfo, merged)
+
+	expected := &transform.Provenance{File: "all_mobs_nineth.json", Row: 1, SourceURL: "http://www.ffxidb.com/items/540", TransformedAt: at}
+	if provenance := mobInfo[0].ItemDrops[0].Provenance; !reflect.DeepEqual(provenance, expected) {
+		t.Errorf("Expected provenance %+v, but got %+v", expected, provenance)
+	}
+}
+
+// TestRunBundledInputs runs the drops transformer with its defaults on the
+// scrape batch and the Python style Valkurm_Dunes.json in this directory. With
+// a single batch the rates are kept as scraped.
+func TestRunBundledInputs(t *testing.T) {
+	opts := transform.Options{InputDir: ".", OutputDir: t.TempDir(), Format: transform.FormatJSON}
+	if err := Run(opts, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	fileContent, err := os.ReadFile(filepath.Join(opts.OutputDir, "Valkurm_Dunes_output.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var mobInfo []MobInfo
+	if err := json.Unmarshal(fileContent, &mobInfo); err != nil {
+		t.Fatal(err)
+	}
+
+	drops := make(map[string]ItemDrop)
+	for _, mob := range mobInfo {
+		for _, drop := range mob.ItemDrops {
+			drops[mob.Name+"|"+drop.Name] = drop
+		}
+	}
+	testCases := []struct {
+		drop    string
+		percent *float64
+		source  string
+	}{
+		{"Snipper|crab apron", floatPtr(9.8), SourceScrape},
+		{"Damselfly|damselfly worm", floatPtr(9.5), SourceScrape},
+		{"Damselfly|beastmen's seal", nil, SourceUnknown},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.drop, func(t *testing.T) {
+			drop, ok := drops[tc.drop]
+			if !ok {
+				t.Fatalf("Expected a drop %s", tc.drop)
+			}
+			if (tc.percent == nil) != (drop.Percent == nil) || (tc.percent != nil && *tc.percent != *drop.Percent) || drop.Source != tc.source {
+				t.Errorf("Expected %s from %s, but got %s from %s", percentText(tc.percent), tc.source, percentText(drop.Percent), drop.Source)
+			}
+		})
+	}
+}
+
+func percentText(percent *float64) string {
+	if percent == nil {
+		return "unknown"
+	}
+	return fmt.Sprintf("%v%%", *percent)
+}
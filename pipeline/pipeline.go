@@ -0,0 +1,223 @@
+// Package pipeline runs a full data build described by a config file.
+package pipeline
+
+import (
+	"encoding/json"
+	"ffxi/harvestpoints"
+	"ffxi/merchants"
+	"ffxi/mobdrops"
+	"ffxi/recipe"
+	"ffxi/transform"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Dataset kinds, one per transformer.
+const (
+	KindRecipes   = "recipes"
+	KindMerchants = "merchants"
+	KindDrops     = "drops"
+	KindHarvest   = "harvest"
+)
+
+// Config describes a data build. Relative directories are resolved against
+// the directory of the config file and dataset output directories against
+// OutputRoot.
+//
+//	{
+//	  "OutputRoot": "../FinalFantasyData",
+//	  "Overwrite": "replace",
+//	  "Profiles": {"merchants-v2": {"goodsPrice": "Goods"}},
+//	  "Datasets": [
+//	    {"Kind": "merchants", "InputDir": "merchants", "Profile": "merchants-v2", "OutputDir": "Merchants"}
+//	  ]
+//	}
+type Config struct {
+	OutputRoot string `json:"OutputRoot"`
+	// Format and Overwrite apply to datasets that don't set their own.
+	Format    string `json:"Format"`
+	Overwrite string `json:"Overwrite"`
+	// Profiles are column mappings datasets refer to by name.
+	Profiles map[string]transform.Profile `json:"Profiles"`
+	Datasets []Dataset                    `json:"Datasets"`
+
+	baseDir string
+}
+
+// Dataset is one transformer run of a build.
+type Dataset struct {
+	// Name labels the dataset in logs and errors, defaulting to Kind.
+	Name string `json:"Name"`
+	Kind string `json:"Kind"`
+	// InputDir holds the exports, Inputs are files or globs within it.
+	// Empty Inputs means the transformer's default files.
+	InputDir  string   `json:"InputDir"`
+	Inputs    []string `json:"Inputs"`
+	Profile   string   `json:"Profile"`
+	OutputDir string   `json:"OutputDir"`
+	Format    string   `json:"Format"`
+	Overwrite string   `json:"Overwrite"`
+
+	// SummaryDir is where recipes writes all_craft.json and item_names.txt,
+	// defaulting to OutputDir.
+	SummaryDir string `json:"SummaryDir,omitempty"`
+	// Zones are the zone mob files drops updates.
+	Zones []string `json:"Zones,omitempty"`
+	// View and PointType configure harvest.
+	View      string `json:"View,omitempty"`
+	PointType string `json:"PointType,omitempty"`
+}
+
+// Load reads a JSON config file. YAML isn't supported as the tree has no
+// YAML parser, convert YAML configs to JSON first.
+func Load(filename string) (*Config, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yaml", ".yml":
+		return nil, fmt.Errorf("%s: YAML configs are not supported, use JSON", filename)
+	}
+
+	fileContent, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg Config
+	err = json.Unmarshal(fileContent, &cfg)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+	cfg.baseDir = filepath.Dir(filename)
+
+	err = cfg.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+	return &cfg, nil
+}
+
+// Validate checks kinds, profiles, formats and policies so a build fails
+// before writing anything.
+func (c *Config) Validate() error {
+	if len(c.Datasets) == 0 {
+		return fmt.Errorf("no datasets")
+	}
+	for _, dataset := range c.Datasets {
+		switch dataset.Kind {
+		case KindRecipes, KindMerchants, KindDrops, KindHarvest:
+		default:
+			return fmt.Errorf("dataset %s: unknown kind %q", dataset.label(), dataset.Kind)
+		}
+		if dataset.OutputDir == "" && c.OutputRoot == "" {
+			return fmt.Errorf("dataset %s: no OutputDir", dataset.label())
+		}
+		if _, ok := c.Profiles[dataset.Profile]; dataset.Profile != "" && !ok {
+			return fmt.Errorf("dataset %s: unknown profile %q", dataset.label(), dataset.Profile)
+		}
+		opts := c.options(dataset, false)
+		if err := opts.CheckFormat(transform.Formats...); err != nil {
+			return fmt.Errorf("dataset %s: %v", dataset.label(), err)
+		}
+		switch opts.Overwrite {
+		case transform.OverwriteReplace, transform.OverwriteSkip, transform.OverwriteMerge:
+		default:
+			return fmt.Errorf("dataset %s: unknown overwrite policy %q", dataset.label(), opts.Overwrite)
+		}
+		if dataset.Kind == KindHarvest {
+			if err := dataset.harvestConfig().Validate(); err != nil {
+				return fmt.Errorf("dataset %s: %v", dataset.label(), err)
+			}
+		}
+	}
+	return nil
+}
+
+// Run builds every dataset in order, stopping at the first failure.
+func (c *Config) Run(verbose bool) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+
+	for _, dataset := range c.Datasets {
+		opts := c.options(dataset, verbose)
+		opts.Logf("Building %s into %s", dataset.label(), opts.OutputDir)
+
+		var err error
+		switch dataset.Kind {
+		case KindRecipes:
+			summaryDir := opts.OutputDir
+			if dataset.SummaryDir != "" {
+				summaryDir = c.path(dataset.SummaryDir)
+			}
+			err = recipe.Run(opts, summaryDir)
+		case KindMerchants:
+			err = merchants.Run(opts)
+		case KindDrops:
+			err = mobdrops.Run(opts, dataset.Zones)
+		case KindHarvest:
+			err = harvestpoints.Run(opts, dataset.harvestConfig())
+		}
+		if err != nil {
+			return fmt.Errorf("dataset %s: %v", dataset.label(), err)
+		}
+	}
+
+	return nil
+}
+
+// options resolves the transformer options of a dataset.
+func (c *Config) options(dataset Dataset, verbose bool) transform.Options {
+	opts := transform.Options{
+		InputDir:  c.path(dataset.InputDir),
+		Inputs:    dataset.Inputs,
+		Profile:   c.Profiles[dataset.Profile],
+		OutputDir: filepath.Join(c.path(c.OutputRoot), dataset.OutputDir),
+		Format:    dataset.Format,
+		Overwrite: dataset.Overwrite,
+		Verbose:   verbose,
+	}
+	if filepath.IsAbs(dataset.OutputDir) {
+		opts.OutputDir = dataset.OutputDir
+	}
+	if opts.Format == "" {
+		opts.Format = c.Format
+	}
+	if opts.Format == "" {
+		opts.Format = transform.FormatJSON
+	}
+	if opts.Overwrite == "" {
+		opts.Overwrite = c.Overwrite
+	}
+	if opts.Overwrite == "" {
+		opts.Overwrite = transform.OverwriteReplace
+	}
+	return opts
+}
+
+// path resolves a config relative path.
+func (c *Config) path(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(c.baseDir, path)
+}
+
+// harvestConfig applies View and PointType over the harvest defaults.
+func (d Dataset) harvestConfig() harvestpoints.Config {
+	cfg := harvestpoints.DefaultConfig
+	if d.View != "" {
+		cfg.View = harvestpoints.View(d.View)
+	}
+	if d.PointType != "" {
+		cfg.PointType = harvestpoints.PointType(d.PointType)
+	}
+	return cfg
+}
+
+func (d Dataset) label() string {
+	if d.Name != "" {
+		return d.Name
+	}
+	return d.Kind
+}
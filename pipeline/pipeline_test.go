@@ -0,0 +1,74 @@
+package pipeline
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadRejectsBadConfigs(t *testing.T) {
+	testCases := []struct {
+		name   string
+		config string
+		err    string
+	}{
+		{"kind", `{"OutputRoot": "out", "Datasets": [{"Kind": "quests"}]}`, "unknown kind"},
+		{"profile", `{"OutputRoot": "out", "Datasets": [{"Kind": "merchants", "Profile": "v2"}]}`, "unknown profile"},
+		{"overwrite", `{"OutputRoot": "out", "Datasets": [{"Kind": "merchants", "Overwrite": "append"}]}`, "unknown overwrite policy"},
+		{"format", `{"OutputRoot": "out", "Format": "xml", "Datasets": [{"Kind": "merchants"}]}`, "unsupported output format"},
+		{"empty", `{"OutputRoot": "out"}`, "no datasets"},
+		{"view", `{"OutputRoot": "out", "Datasets": [{"Kind": "harvest", "View": "count"}]}`, "unknown view"},
+		{"point type", `{"OutputRoot": "out", "Datasets": [{"Kind": "harvest", "PointType": "gardening"}]}`, "unknown point type"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			configFile := filepath.Join(t.TempDir(), "pipeline.json")
+			if err := os.WriteFile(configFile, []byte(tc.config), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := Load(configFile)
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("Expected error containing %q, but got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestRunMerchantsWithProfile(t *testing.T) {
+	dir := t.TempDir()
+	input := `[{"Vendor": "Dahjal", "type": "Item Merchant", "goodsPrice": "Ash Log 90-110 gil", "location": "Port Bastok (H-7)"}]`
+	if err := os.MkdirAll(filepath.Join(dir, "exports"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "exports", "vendors.json"), []byte(input), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config := `{
+		"OutputRoot": "data",
+		"Profiles": {"vendors": {"merchant": "Vendor"}},
+		"Datasets": [{"Kind": "merchants", "InputDir": "exports", "Inputs": ["vendors.json"], "Profile": "vendors", "OutputDir": "Merchants"}]
+	}`
+	configFile := filepath.Join(dir, "pipeline.json")
+	if err := os.WriteFile(configFile, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Run(false); err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := os.ReadFile(filepath.Join(dir, "data", "Merchants", "Port_Bastok.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(output), `"Name": "Dahjal"`) {
+		t.Errorf("Expected Dahjal in the output, but got %s", output)
+	}
+}
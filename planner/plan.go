@@ -0,0 +1,436 @@
+// Package planner finds the cheapest way to get an item for a character by
+// buying, crafting, farming or gathering it and its ingredients.
+package planner
+
+import (
+	"ffxi/farming"
+	"ffxi/item"
+	"ffxi/recipe"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Ways of getting an item.
+const (
+	MethodBuy         = "buy"
+	MethodAuction     = "auction"
+	MethodCraft       = "craft"
+	MethodFarm        = "farm"
+	MethodGather      = "gather"
+	MethodUnavailable = "unavailable"
+)
+
+// Cost modes of Options.Mode.
+const (
+	ModeGil      = "gil"
+	ModeTime     = "time"
+	ModeWeighted = "weighted"
+)
+
+// Character is the player the plan is made for.
+type Character struct {
+	Level int
+	// Skills maps craft to skill level. A nil map means no skill limit.
+	Skills map[string]int
+}
+
+// Options tunes how options are costed. Zero values use the defaults below.
+type Options struct {
+	// Mode is ModeGil, ModeTime or ModeWeighted. ModeGil only compares gil
+	// spent, so farming, which costs kills rather than gil, is left out of
+	// it. ModeTime and ModeWeighted consider farming.
+	Mode string
+	// GilPerSecond converts time into gil for ModeWeighted.
+	GilPerSecond float64
+
+	SecondsPerPurchase float64
+	SecondsPerKill     float64
+	SecondsPerGather   float64
+	SecondsPerSynth    float64
+	// StackSize is the stack size assumed for auction house stack prices.
+	StackSize int
+	// ToolBreakRate is the chance a gathering tool breaks on each attempt.
+	ToolBreakRate float64
+	// MaxLevelsAbove skips mobs whose minimum level is more than this many
+	// levels above the character.
+	MaxLevelsAbove int
+}
+
+// Defaults applied to zero-valued Options fields.
+const (
+	DefaultGilPerSecond       = 1
+	DefaultSecondsPerPurchase = 60
+	DefaultSecondsPerKill     = 60
+	DefaultSecondsPerGather   = 10
+	DefaultSecondsPerSynth    = 15
+	DefaultToolBreakRate      = 0.1
+	DefaultStackSize          = 12
+)
+
+// Step is one node of a plan tree. Craft steps have the steps for their
+// ingredients as Inputs. Alternatives lists the other options that were
+// costed for the same item, cheapest first.
+type Step struct {
+	Item         string        `json:"Item"`
+	Count        int           `json:"Count"`
+	Method       string        `json:"Method"`
+	Source       string        `json:"Source,omitempty"`
+	Gil          float64       `json:"Gil"`
+	Seconds      float64       `json:"Seconds"`
+	Explanation  string        `json:"Explanation"`
+	Inputs       []*Step       `json:"Inputs,omitempty"`
+	Alternatives []Alternative `json:"Alternatives,omitempty"`
+}
+
+// Alternative is an option that lost to the chosen one.
+type Alternative struct {
+	Method  string  `json:"Method"`
+	Source  string  `json:"Source"`
+	Gil     float64 `json:"Gil"`
+	Seconds float64 `json:"Seconds"`
+}
+
+// Planner finds the cheapest way to get items from a catalog and recipes.
+type Planner struct {
+	catalog   *item.Catalog
+	recipes   map[string][]recipe.CraftingRecipe
+	character Character
+	opts      Options
+
+	memo     map[string]*Step
+	visiting map[string]bool
+	// pruned are the items whose recipes and tool gathering were skipped
+	// because the item was being visited, in the order they were skipped.
+	pruned []string
+}
+
+// New returns a planner for a character. Recipes are indexed by result.
+func New(catalog *item.Catalog, recipes []recipe.CraftingRecipe, character Character, opts Options) *Planner {
+	p := &Planner{
+		catalog:   catalog,
+		recipes:   make(map[string][]recipe.CraftingRecipe),
+		character: character,
+		opts:      withDefaults(opts),
+		memo:      make(map[string]*Step),
+		visiting:  make(map[string]bool),
+	}
+	for _, r := range recipes {
+		p.recipes[item.Key(r.Result)] = append(p.recipes[item.Key(r.Result)], r)
+	}
+	return p
+}
+
+// Plan returns the cheapest plan to get count of an item. Items with no
+// usable source get an unavailable step.
+func (p *Planner) Plan(name string, count int) *Step {
+	memoKey := fmt.Sprintf("%s|%d", item.Key(name), count)
+	if step, ok := p.memo[memoKey]; ok {
+		return step
+	}
+	prunedBefore := len(p.pruned)
+
+	entry, known := p.catalog.Lookup(name)
+	if known {
+		name = entry.Name
+	}
+	// Recipes and gathering tools that need the item somewhere below it
+	// can't provide it, so an item being visited skips both
+	key := item.Key(name)
+	visited := p.visiting[key]
+	if visited {
+		p.pruned = append(p.pruned, key)
+	} else {
+		p.visiting[key] = true
+	}
+
+	var options []*Step
+	if known {
+		options = append(options, p.buyOptions(entry, count)...)
+		options = append(options, p.auctionOptions(entry, count)...)
+		if p.opts.Mode != ModeGil {
+			options = append(options, p.farmOptions(entry, count)...)
+		}
+		options = append(options, p.gatherOptions(entry, count, !visited)...)
+	}
+	if !visited {
+		options = append(options, p.craftOptions(name, count)...)
+		delete(p.visiting, key)
+	}
+
+	sort.SliceStable(options, func(i, j int) bool {
+		return p.score(options[i]) < p.score(options[j])
+	})
+
+	var best *Step
+	if len(options) == 0 {
+		best = &Step{
+			Item:        name,
+			Count:       count,
+			Method:      MethodUnavailable,
+			Explanation: fmt.Sprintf("No vendor, mob, gathering point or craftable recipe provides %s", name),
+		}
+	} else {
+		best = options[0]
+		for _, option := range options[1:] {
+			best.Alternatives = append(best.Alternatives, Alternative{
+				Method:  option.Method,
+				Source:  option.Source,
+				Gil:     option.Gil,
+				Seconds: option.Seconds,
+			})
+		}
+		if len(options) > 1 {
+			best.Explanation += fmt.Sprintf("; cheaper than %s from %s", options[1].Method, options[1].Source)
+		}
+	}
+
+	// Steps that skipped options of an item still being visited above them
+	// depend on the path they were reached by, so only memoize the others
+	if !p.prunedByAncestor(prunedBefore) {
+		p.memo[memoKey] = best
+		p.pruned = p.pruned[:prunedBefore]
+	}
+	return best
+}
+
+// prunedByAncestor reports whether options were skipped since the first
+// pruned items because of an item that is still being visited.
+func (p *Planner) prunedByAncestor(first int) bool {
+	for _, key := range p.pruned[first:] {
+		if p.visiting[key] {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Planner) buyOptions(entry *item.Entry, count int) []*Step {
+	var options []*Step
+	for _, vendor := range entry.Vendors {
+		// Prices drop with fame, plan with the worst price
+		gil := float64(vendor.MaxPrice * count)
+		options = append(options, &Step{
+			Item:        entry.Name,
+			Count:       count,
+			Method:      MethodBuy,
+			Source:      fmt.Sprintf("%s in %s", vendor.Merchant, vendor.Zone),
+			Gil:         gil,
+			Seconds:     p.opts.SecondsPerPurchase,
+			Explanation: fmt.Sprintf("Buy %d from %s in %s at up to %d gil each", count, vendor.Merchant, vendor.Zone, vendor.MaxPrice),
+		})
+	}
+	return options
+}
+
+// auctionOptions buys from the auction house at the market price attached to
+// the catalog, as a single or, when cheaper per item, as a stack.
+func (p *Planner) auctionOptions(entry *item.Entry, count int) []*Step {
+	if entry.Market == nil {
+		return nil
+	}
+
+	var options []*Step
+	if entry.Market.Single != nil {
+		options = append(options, &Step{
+			Item:        entry.Name,
+			Count:       count,
+			Method:      MethodAuction,
+			Source:      "auction house singles",
+			Gil:         *entry.Market.Single * float64(count),
+			Seconds:     p.opts.SecondsPerPurchase,
+			Explanation: fmt.Sprintf("Buy %d singles at the auction house for about %.0f gil each (as of %s)", count, *entry.Market.Single, entry.Market.Date),
+		})
+	}
+	if entry.Market.Stack != nil && p.opts.StackSize > 1 {
+		stacks := math.Ceil(float64(count) / float64(p.opts.StackSize))
+		options = append(options, &Step{
+			Item:        entry.Name,
+			Count:       count,
+			Method:      MethodAuction,
+			Source:      "auction house stacks",
+			Gil:         *entry.Market.Stack * stacks,
+			Seconds:     p.opts.SecondsPerPurchase,
+			Explanation: fmt.Sprintf("Buy %.0f stacks at the auction house for about %.0f gil each (as of %s)", stacks, *entry.Market.Stack, entry.Market.Date),
+		})
+	}
+	return options
+}
+
+func (p *Planner) farmOptions(entry *item.Entry, count int) []*Step {
+	var options []*Step
+	for _, mob := range entry.Mobs {
+		if mob.Percent == nil || *mob.Percent <= 0 {
+			continue
+		}
+		if mob.LevelRange != nil && p.character.Level > 0 && mob.LevelRange.Min > p.character.Level+p.opts.MaxLevelsAbove {
+			continue
+		}
+
+		kills := float64(count) / (*mob.Percent / 100)
+		options = append(options, &Step{
+			Item:        entry.Name,
+			Count:       count,
+			Method:      MethodFarm,
+			Source:      fmt.Sprintf("%s in %s", mob.Mob, mob.Zone),
+			Seconds:     kills * p.opts.SecondsPerKill,
+			Explanation: fmt.Sprintf("Defeat about %.0f %s in %s at a %v%% drop rate", math.Ceil(kills), mob.Mob, mob.Zone, *mob.Percent),
+		})
+	}
+	return options
+}
+
+// gatherOptions gathers the item at its gathering points. Points that need a
+// tool are left out unless withTools is set.
+func (p *Planner) gatherOptions(entry *item.Entry, count int, withTools bool) []*Step {
+	var options []*Step
+	for _, point := range entry.GatheringPoints {
+		if point.Percent == nil || *point.Percent <= 0 {
+			continue
+		}
+		if point.RequiredTool != "" && !withTools {
+			continue
+		}
+
+		attempts := float64(count) / (*point.Percent / 100)
+		var gil float64
+		if point.RequiredTool != "" {
+			tool := p.Plan(point.RequiredTool, 1)
+			if tool.Method == MethodUnavailable {
+				continue
+			}
+			gil = tool.Gil * attempts * p.opts.ToolBreakRate
+		}
+
+		options = append(options, &Step{
+			Item:        entry.Name,
+			Count:       count,
+			Method:      MethodGather,
+			Source:      fmt.Sprintf("%s point in %s", point.PointType, point.Zone),
+			Gil:         gil,
+			Seconds:     attempts * p.opts.SecondsPerGather,
+			Explanation: fmt.Sprintf("Gather about %.0f times at %s points in %s with a %s at a %v%% yield", math.Ceil(attempts), point.PointType, point.Zone, point.RequiredTool, *point.Percent),
+		})
+	}
+	return options
+}
+
+func (p *Planner) craftOptions(name string, count int) []*Step {
+	var options []*Step
+	for _, r := range p.recipes[item.Key(name)] {
+		if !p.canCraft(r) {
+			continue
+		}
+
+		yield := nqYield(r)
+		synths := int(math.Ceil(float64(count) / float64(yield)))
+		step := &Step{
+			Item:    name,
+			Count:   count,
+			Method:  MethodCraft,
+			Source:  r.Name,
+			Seconds: float64(synths) * p.opts.SecondsPerSynth,
+			Explanation: fmt.Sprintf("Synthesize %d times with %s %d (%s), %d per synth",
+				synths, r.MainCraft, r.SkillLevels[r.MainCraft], r.Crystal, yield),
+		}
+
+		ingredients := append([]recipe.Item{}, r.RequiredItems...)
+		if r.Crystal != "" {
+			ingredients = append(ingredients, recipe.Item{Name: item.CrystalName(r.Crystal), Count: 1})
+		}
+		available := true
+		for _, ingredient := range ingredients {
+			input := p.Plan(ingredient.Name, ingredient.Count*synths)
+			if input.Method == MethodUnavailable {
+				available = false
+				break
+			}
+			step.Inputs = append(step.Inputs, input)
+			step.Gil += input.Gil
+			step.Seconds += input.Seconds
+		}
+		if !available {
+			continue
+		}
+
+		options = append(options, step)
+	}
+	return options
+}
+
+// canCraft checks every skill the recipe needs against the character.
+func (p *Planner) canCraft(r recipe.CraftingRecipe) bool {
+	if p.character.Skills == nil {
+		return true
+	}
+	for craft, level := range r.SkillLevels {
+		if skillOf(p.character.Skills, craft) < level {
+			return false
+		}
+	}
+	return true
+}
+
+func skillOf(skills map[string]int, craft string) int {
+	for name, level := range skills {
+		if strings.EqualFold(name, craft) {
+			return level
+		}
+	}
+	return 0
+}
+
+// nqYield is how many items a normal quality synth makes.
+func nqYield(r recipe.CraftingRecipe) int {
+	for _, result := range r.AllPossibleResults {
+		if result.HighQualityLevel == 0 && result.Count > 0 {
+			return result.Count
+		}
+	}
+	return 1
+}
+
+// score is the cost of a step in the configured mode, lower is cheaper.
+func (p *Planner) score(step *Step) float64 {
+	switch p.opts.Mode {
+	case ModeTime:
+		return step.Seconds
+	case ModeWeighted:
+		return step.Gil + step.Seconds*p.opts.GilPerSecond
+	default:
+		// Break gil ties by time so free options prefer the quicker one
+		return step.Gil + step.Seconds*1e-9
+	}
+}
+
+func withDefaults(opts Options) Options {
+	if opts.Mode == "" {
+		opts.Mode = ModeGil
+	}
+	if opts.GilPerSecond == 0 {
+		opts.GilPerSecond = DefaultGilPerSecond
+	}
+	if opts.SecondsPerPurchase == 0 {
+		opts.SecondsPerPurchase = DefaultSecondsPerPurchase
+	}
+	if opts.SecondsPerKill == 0 {
+		opts.SecondsPerKill = DefaultSecondsPerKill
+	}
+	if opts.SecondsPerGather == 0 {
+		opts.SecondsPerGather = DefaultSecondsPerGather
+	}
+	if opts.SecondsPerSynth == 0 {
+		opts.SecondsPerSynth = DefaultSecondsPerSynth
+	}
+	if opts.StackSize == 0 {
+		opts.StackSize = DefaultStackSize
+	}
+	if opts.ToolBreakRate == 0 {
+		opts.ToolBreakRate = DefaultToolBreakRate
+	}
+	if opts.MaxLevelsAbove == 0 {
+		opts.MaxLevelsAbove = farming.DefaultMaxLevelsAbove
+	}
+	return opts
+}
@@ -0,0 +1,163 @@
+package planner
+
+import (
+	"ffxi/harvestpoints"
+	"ffxi/item"
+	"ffxi/merchants"
+	"ffxi/mobdrops"
+	"ffxi/recipe"
+	"testing"
+)
+
+func testCatalog() (*item.Catalog, []recipe.CraftingRecipe) {
+	percent := func(f float64) *float64 { return &f }
+	recipes := []recipe.CraftingRecipe{
+		{
+			Name:               "Woodworking-7-Ash Lumber-From-1-Ash Log",
+			Result:             "Ash Lumber",
+			Crystal:            "Wind",
+			MainCraft:          "Woodworking",
+			SkillLevels:        map[string]int{"Woodworking": 7},
+			RequiredItems:      []recipe.Item{{Name: "Ash Log", Count: 1}},
+			AllPossibleResults: []recipe.ResultsIncludingHighQuality{{Name: "Ash Lumber", Count: 1}},
+		},
+	}
+
+	catalog := item.NewCatalog()
+	catalog.AddRecipes(recipes)
+	catalog.AddMerchants([]merchants.MerchantInfo{
+		{Name: "Dahjal", Zone: "Port_Bastok", Items: []merchants.ItemInfo{
+			{Name: "Ash Log", MinPrice: 90, MaxPrice: 100},
+			{Name: "Wind Crystal", MinPrice: 15, MaxPrice: 20},
+			{Name: "Ash Lumber", MinPrice: 180, MaxPrice: 200},
+		}},
+	})
+	catalog.AddMobs([]mobdrops.MobInfo{
+		{Name: "Treant Sapling", ZoneName: "Jugner_Forest", LevelRange: &mobdrops.LevelRange{Min: 30, Max: 32},
+			ItemDrops: []mobdrops.ItemDrop{{Name: "Ash Log", Percent: percent(50)}}},
+	})
+	return catalog, recipes
+}
+
+func TestPlan(t *testing.T) {
+	catalog, recipes := testCatalog()
+
+	testCases := []struct {
+		name      string
+		character Character
+		opts      Options
+		method    string
+		gil       float64
+		// Whether other options remain to be listed as alternatives
+		alternatives bool
+	}{
+		// Crafting from bought logs beats buying lumber
+		{"gil", Character{Level: 10, Skills: map[string]int{"Woodworking": 10}}, Options{}, MethodCraft, 2 * (100 + 20), true},
+		// Farmed logs cost no gil but aren't free, gil plans keep buying them
+		{"gil without farming", Character{Level: 30, Skills: map[string]int{"Woodworking": 10}}, Options{}, MethodCraft, 2 * (100 + 20), true},
+		// Weighing time, 4 treant kills for the logs beat buying them
+		{"weighted farming", Character{Level: 30, Skills: map[string]int{"Woodworking": 10}}, Options{Mode: ModeWeighted}, MethodCraft, 2 * 20, true},
+		// Without the skill the lumber has to be bought
+		{"no skill", Character{Level: 10, Skills: map[string]int{"Woodworking": 5}}, Options{}, MethodBuy, 2 * 200, false},
+		// One purchase trip is quicker than buying and crafting
+		{"time", Character{Level: 10}, Options{Mode: ModeTime}, MethodBuy, 2 * 200, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			step := New(catalog, recipes, tc.character, tc.opts).Plan("Ash Lumber", 2)
+			if step.Method != tc.method || step.Gil != tc.gil {
+				t.Errorf("Expected %s for %v gil, but got %s for %v gil: %s", tc.method, tc.gil, step.Method, step.Gil, step.Explanation)
+			}
+			if (len(step.Alternatives) > 0) != tc.alternatives {
+				t.Errorf("Expected alternatives %v, but got %+v", tc.alternatives, step.Alternatives)
+			}
+		})
+	}
+}
+
+func TestPlanUnavailable(t *testing.T) {
+	catalog, recipes := testCatalog()
+
+	step := New(catalog, recipes, Character{Level: 10}, Options{}).Plan("Oak Lumber", 1)
+	if step.Method != MethodUnavailable {
+		t.Errorf("Expected an unavailable step, but got %s", step.Method)
+	}
+}
+
+func TestPlanAuction(t *testing.T) {
+	catalog, recipes := testCatalog()
+	single, stack := 150.0, 1200.0
+	catalog.SetMarketPrice("Ash Lumber", item.MarketPrice{Single: &single, Stack: &stack, Observations: 3, Date: "2026-10-14"})
+
+	step := New(catalog, recipes, Character{Level: 10, Skills: map[string]int{"Woodworking": 5}}, Options{}).Plan("Ash Lumber", 12)
+	if step.Method != MethodAuction || step.Gil != 1200 {
+		t.Errorf("Expected one auction house stack for 1200 gil, but got %s for %v gil", step.Method, step.Gil)
+	}
+}
+
+func TestPlanCycle(t *testing.T) {
+	craft := func(result, ingredient string) recipe.CraftingRecipe {
+		return recipe.CraftingRecipe{
+			Name:          "Smithing-1-" + result + "-From-1-" + ingredient,
+			Result:        result,
+			MainCraft:     "Smithing",
+			SkillLevels:   map[string]int{"Smithing": 1},
+			RequiredItems: []recipe.Item{{Name: ingredient, Count: 1}},
+		}
+	}
+	// Sheet -> Ingot -> Scales -> Sheet is a cycle, the ingot is also made
+	// from cheap ore
+	recipes := []recipe.CraftingRecipe{
+		craft("Bronze Sheet", "Bronze Ingot"),
+		craft("Bronze Ingot", "Copper Ore"),
+		craft("Bronze Ingot", "Bronze Scales"),
+		craft("Bronze Scales", "Bronze Sheet"),
+	}
+	catalog := item.NewCatalog()
+	catalog.AddRecipes(recipes)
+	catalog.AddMerchants([]merchants.MerchantInfo{
+		{Name: "Mjoll", Zone: "Port_Bastok", Items: []merchants.ItemInfo{
+			{Name: "Copper Ore", MinPrice: 1, MaxPrice: 1},
+			{Name: "Bronze Sheet", MinPrice: 50, MaxPrice: 50},
+			{Name: "Bronze Scales", MinPrice: 1000, MaxPrice: 1000},
+		}},
+	})
+
+	planner := New(catalog, recipes, Character{Level: 10}, Options{})
+	planner.Plan("Bronze Sheet", 1)
+	// Planning the sheet costed the scales from bought sheets as the sheet
+	// recipe was being visited, which must not be reused here
+	step := planner.Plan("Bronze Scales", 1)
+	if step.Method != MethodCraft || step.Gil != 1 {
+		t.Errorf("Expected scales crafted from ore for 1 gil, but got %s for %v gil: %s", step.Method, step.Gil, step.Explanation)
+	}
+}
+
+func TestPlanToolCycle(t *testing.T) {
+	percent := func(f float64) *float64 { return &f }
+	catalog := item.NewCatalog()
+	// The pickaxe can only be dug up with a pickaxe
+	catalog.AddGatheringPoints([]harvestpoints.HarvestPoint{
+		{Name: "Mining Point", PointType: harvestpoints.Mining, ZoneName: "Zeruhn_Mines", RequiredTool: "Pickaxe",
+			ItemDropInfos: []harvestpoints.ItemDropInfo{
+				{Name: "Pickaxe", Percent: percent(5)},
+				{Name: "Copper Ore", Percent: percent(50)},
+			}},
+	})
+
+	planner := New(catalog, nil, Character{Level: 10}, Options{})
+	for _, name := range []string{"Pickaxe", "Copper Ore"} {
+		if step := planner.Plan(name, 1); step.Method != MethodUnavailable {
+			t.Errorf("Expected %s to be unavailable without a pickaxe, but got %s: %s", name, step.Method, step.Explanation)
+		}
+	}
+
+	catalog.AddMerchants([]merchants.MerchantInfo{
+		{Name: "Mjoll", Zone: "Port_Bastok", Items: []merchants.ItemInfo{{Name: "Pickaxe", MinPrice: 200, MaxPrice: 200}}},
+	})
+	step := New(catalog, nil, Character{Level: 10}, Options{}).Plan("Copper Ore", 1)
+	if step.Method != MethodGather {
+		t.Errorf("Expected ore gathered with a bought pickaxe, but got %s: %s", step.Method, step.Explanation)
+	}
+}
@@ -0,0 +1,370 @@
+// Package profit calculates the profit of crafting each recipe and writes
+// reports per craft and level band.
+package profit
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"ffxi/item"
+	"ffxi/recipe"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Merchant price used for ingredients, merchants sell in a fame based range.
+const (
+	PriceMin = "min"
+	PriceMax = "max"
+	PriceMid = "mid"
+)
+
+// Prices looks up the value of an item that isn't priced by merchants.
+type Prices interface {
+	Price(name string) (float64, bool)
+}
+
+// PriceTable is a user supplied item -> gil price table keyed by item.Key.
+type PriceTable map[string]float64
+
+// Price returns the price of an item by any spelling.
+func (t PriceTable) Price(name string) (float64, bool) {
+	price, ok := t[item.Key(name)]
+	return price, ok
+}
+
+// PriceChain asks each Prices in turn, so a user table can override auction
+// house prices.
+type PriceChain []Prices
+
+// Price returns the first price found.
+func (c PriceChain) Price(name string) (float64, bool) {
+	for _, prices := range c {
+		if price, ok := prices.Price(name); ok {
+			return price, true
+		}
+	}
+	return 0, false
+}
+
+// LoadPriceTable reads a JSON object of item -> price, or a CSV file of
+// item,price rows with an optional header.
+func LoadPriceTable(filename string) (PriceTable, error) {
+	fileContent, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	table := make(PriceTable)
+	if strings.HasSuffix(strings.ToLower(filename), ".csv") {
+		records, err := csv.NewReader(strings.NewReader(string(fileContent))).ReadAll()
+		if err != nil {
+			return nil, err
+		}
+		for i, record := range records {
+			if len(record) < 2 {
+				return nil, fmt.Errorf("%s line %d: expected item,price", filename, i+1)
+			}
+			price, err := strconv.ParseFloat(strings.TrimSpace(record[1]), 64)
+			if err != nil {
+				if i == 0 {
+					// Header row
+					continue
+				}
+				return nil, fmt.Errorf("%s line %d: %v", filename, i+1, err)
+			}
+			table[item.Key(record[0])] = price
+		}
+		return table, nil
+	}
+
+	var prices map[string]float64
+	if err := json.Unmarshal(fileContent, &prices); err != nil {
+		return nil, err
+	}
+	for name, price := range prices {
+		table[item.Key(name)] = price
+	}
+	return table, nil
+}
+
+// ParseHQRates reads HQ rates written as level:chance pairs, e.g.
+// "1:0.05,2:0.01,3:0.005". Chances are fractions between 0 and 1.
+func ParseHQRates(list string) (map[int]float64, error) {
+	rates := make(map[int]float64)
+	for _, pair := range strings.Split(list, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		parts := strings.Split(pair, ":")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("HQ rate %q: expected level:chance", pair)
+		}
+		level, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+		if err != nil || level < 1 {
+			return nil, fmt.Errorf("HQ rate %q: level must be a positive number", pair)
+		}
+		chance, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+		if err != nil || chance < 0 || chance > 1 {
+			return nil, fmt.Errorf("HQ rate %q: chance must be between 0 and 1", pair)
+		}
+		rates[level] = chance
+	}
+	return rates, nil
+}
+
+// Options configures the calculator. Zero values use the defaults.
+type Options struct {
+	// MerchantPrice is PriceMin, PriceMax or PriceMid.
+	MerchantPrice string
+	// HQRates maps HQ level to the chance of that result per synth.
+	HQRates map[int]float64
+	// LevelBandSize groups recipes into level bands of this size.
+	LevelBandSize int
+}
+
+// Defaults applied to zero-valued Options fields.
+var (
+	DefaultMerchantPrice = PriceMax
+	DefaultHQRates       = map[int]float64{1: 0.05, 2: 0.01, 3: 0.005}
+	DefaultLevelBandSize = 10
+)
+
+// Row is the profit of a single recipe.
+type Row struct {
+	Craft          string   `json:"Craft"`
+	Level          int      `json:"Level"`
+	LevelBand      string   `json:"LevelBand"`
+	Recipe         string   `json:"Recipe"`
+	Result         string   `json:"Result"`
+	IngredientCost float64  `json:"IngredientCost"`
+	ExpectedValue  float64  `json:"ExpectedValue"`
+	Profit         float64  `json:"Profit"`
+	Margin         float64  `json:"Margin"`
+	MissingPrices  []string `json:"MissingPrices,omitempty"`
+}
+
+// Calculate returns the profit of every recipe, most profitable first.
+// Ingredients are priced from the catalog's merchants and fall back to
+// prices, results are always valued from prices. Items priced by neither are
+// listed in MissingPrices and count as free, so those rows aren't comparable.
+// A nil prices values nothing.
+func Calculate(recipes []recipe.CraftingRecipe, catalog *item.Catalog, prices Prices, opts Options) []Row {
+	opts = withDefaults(opts)
+	if prices == nil {
+		prices = PriceTable{}
+	}
+
+	var rows []Row
+	for _, r := range recipes {
+		level := r.SkillLevels[r.MainCraft]
+		row := Row{
+			Craft:     r.MainCraft,
+			Level:     level,
+			LevelBand: levelBand(level, opts.LevelBandSize),
+			Recipe:    r.Name,
+			Result:    r.Result,
+		}
+
+		ingredients := append([]recipe.Item{}, r.RequiredItems...)
+		if r.Crystal != "" {
+			ingredients = append(ingredients, recipe.Item{Name: item.CrystalName(r.Crystal), Count: 1})
+		}
+		for _, ingredient := range ingredients {
+			price, ok := ingredientPrice(ingredient.Name, catalog, prices, opts.MerchantPrice)
+			if !ok {
+				row.MissingPrices = append(row.MissingPrices, ingredient.Name)
+				continue
+			}
+			row.IngredientCost += price * float64(ingredient.Count)
+		}
+
+		row.ExpectedValue = expectedValue(r, prices, opts.HQRates, &row.MissingPrices)
+		row.Profit = row.ExpectedValue - row.IngredientCost
+		if row.IngredientCost > 0 {
+			row.Margin = row.Profit / row.IngredientCost
+		}
+		rows = append(rows, row)
+	}
+
+	SortRows(rows, "Profit")
+	return rows
+}
+
+// ingredientPrice prices an ingredient from its cheapest merchant, then prices.
+func ingredientPrice(name string, catalog *item.Catalog, prices Prices, merchantPrice string) (float64, bool) {
+	if entry, ok := catalog.Lookup(name); ok && len(entry.Vendors) > 0 {
+		cheapest := -1.0
+		for _, vendor := range entry.Vendors {
+			price := float64(vendor.MaxPrice)
+			switch merchantPrice {
+			case PriceMin:
+				price = float64(vendor.MinPrice)
+			case PriceMid:
+				price = float64(vendor.MinPrice+vendor.MaxPrice) / 2
+			}
+			if cheapest < 0 || price < cheapest {
+				cheapest = price
+			}
+		}
+		return cheapest, true
+	}
+	return prices.Price(name)
+}
+
+// expectedValue weights the value of each result by its HQ rate. The NQ
+// result gets whatever chance the HQ results leave.
+func expectedValue(r recipe.CraftingRecipe, prices Prices, hqRates map[int]float64, missing *[]string) float64 {
+	results := r.AllPossibleResults
+	if len(results) == 0 {
+		results = []recipe.ResultsIncludingHighQuality{{Name: r.Result, Count: 1}}
+	}
+
+	nqRate := 1.0
+	for _, result := range results {
+		if result.HighQualityLevel > 0 {
+			nqRate -= hqRates[result.HighQualityLevel]
+		}
+	}
+
+	var value float64
+	for _, result := range results {
+		rate := nqRate
+		if result.HighQualityLevel > 0 {
+			rate = hqRates[result.HighQualityLevel]
+		}
+		price, ok := prices.Price(result.Name)
+		if !ok {
+			*missing = append(*missing, result.Name)
+			continue
+		}
+		value += rate * price * float64(result.Count)
+	}
+	return value
+}
+
+func levelBand(level, size int) string {
+	low := (level-1)/size*size + 1
+	if level <= 0 {
+		low = 0
+	}
+	return fmt.Sprintf("%d-%d", low, low+size-1)
+}
+
+// SortRows sorts rows by a column, numeric columns highest first and text
+// columns alphabetically.
+func SortRows(rows []Row, column string) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		switch column {
+		case "Level":
+			return a.Level > b.Level
+		case "IngredientCost":
+			return a.IngredientCost > b.IngredientCost
+		case "ExpectedValue":
+			return a.ExpectedValue > b.ExpectedValue
+		case "Margin":
+			return a.Margin > b.Margin
+		case "Craft":
+			return a.Craft < b.Craft
+		case "Recipe":
+			return a.Recipe < b.Recipe
+		case "Result":
+			return a.Result < b.Result
+		default:
+			return a.Profit > b.Profit
+		}
+	})
+}
+
+// WriteReports writes a JSON and a CSV report per craft and level band into
+// dir, e.g. profit_Woodworking_1-10.csv.
+func WriteReports(dir string, rows []Row) error {
+	err := os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	groups := make(map[string][]Row)
+	for _, row := range rows {
+		name := fmt.Sprintf("profit_%s_%s", row.Craft, row.LevelBand)
+		groups[name] = append(groups[name], row)
+	}
+
+	for name, group := range groups {
+		jsonData, err := json.MarshalIndent(group, "", "  ")
+		if err != nil {
+			return err
+		}
+		err = os.WriteFile(filepath.Join(dir, name+".json"), jsonData, 0644)
+		if err != nil {
+			return err
+		}
+
+		csvFile, err := os.Create(filepath.Join(dir, name+".csv"))
+		if err != nil {
+			return err
+		}
+		err = WriteCSV(csvFile, group)
+		closeErr := csvFile.Close()
+		if err != nil {
+			return err
+		}
+		if closeErr != nil {
+			return closeErr
+		}
+	}
+
+	return nil
+}
+
+// WriteCSV writes rows as CSV with a header.
+func WriteCSV(w io.Writer, rows []Row) error {
+	writer := csv.NewWriter(w)
+	err := writer.Write([]string{"Craft", "Level", "LevelBand", "Recipe", "Result", "IngredientCost", "ExpectedValue", "Profit", "Margin", "MissingPrices"})
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		err = writer.Write([]string{
+			row.Craft,
+			strconv.Itoa(row.Level),
+			row.LevelBand,
+			row.Recipe,
+			row.Result,
+			formatFloat(row.IngredientCost),
+			formatFloat(row.ExpectedValue),
+			formatFloat(row.Profit),
+			formatFloat(row.Margin),
+			strings.Join(row.MissingPrices, "; "),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', 2, 64)
+}
+
+func withDefaults(opts Options) Options {
+	if opts.MerchantPrice == "" {
+		opts.MerchantPrice = DefaultMerchantPrice
+	}
+	if opts.HQRates == nil {
+		opts.HQRates = DefaultHQRates
+	}
+	if opts.LevelBandSize == 0 {
+		opts.LevelBandSize = DefaultLevelBandSize
+	}
+	return opts
+}
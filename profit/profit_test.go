@@ -0,0 +1,123 @@
+package profit
+
+import (
+	"bytes"
+	"ffxi/item"
+	"ffxi/merchants"
+	"ffxi/recipe"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCalculate(t *testing.T) {
+	recipes := []recipe.CraftingRecipe{
+		{
+			Name:          "Woodworking-7-Ash Lumber-From-1-Ash Log",
+			Result:        "Ash Lumber",
+			Crystal:       "Wind",
+			MainCraft:     "Woodworking",
+			SkillLevels:   map[string]int{"Woodworking": 7},
+			RequiredItems: []recipe.Item{{Name: "Ash Log", Count: 1}},
+			AllPossibleResults: []recipe.ResultsIncludingHighQuality{
+				{Name: "Ash Lumber", Count: 2, HighQualityLevel: 1},
+				{Name: "Ash Lumber", Count: 1},
+			},
+		},
+		{
+			Name:          "Woodworking-12-Ash Pole-From-2-Ash Lumber",
+			Result:        "Ash Pole",
+			Crystal:       "Wind",
+			MainCraft:     "Woodworking",
+			SkillLevels:   map[string]int{"Woodworking": 12},
+			RequiredItems: []recipe.Item{{Name: "Ash Lumber", Count: 2}, {Name: "Rare Sap", Count: 1}},
+		},
+	}
+
+	catalog := item.NewCatalog()
+	catalog.AddMerchants([]merchants.MerchantInfo{
+		{Name: "Dahjal", Zone: "Port_Bastok", Items: []merchants.ItemInfo{
+			{Name: "Ash Log", MinPrice: 90, MaxPrice: 110},
+			{Name: "Wind Crystal", MinPrice: 20, MaxPrice: 20},
+		}},
+	})
+	prices := PriceTable{"ash log": 1, "ash lumber": 300, "ash pole": 500}
+
+	rows := Calculate(recipes, catalog, prices, Options{MerchantPrice: PriceMid, HQRates: map[int]float64{1: 0.1}})
+
+	if len(rows) != 2 {
+		t.Fatalf("Expected 2 rows, but got %d", len(rows))
+	}
+	lumber, pole := rows[0], rows[1]
+
+	// Merchant price wins over the price table, HQ1 doubles the yield 10% of the time
+	if lumber.IngredientCost != 120 || math.Abs(lumber.ExpectedValue-330) > 1e-9 || lumber.LevelBand != "1-10" {
+		t.Errorf("Unexpected lumber row %+v", lumber)
+	}
+	// Lumber isn't sold so it is priced from the table, Rare Sap has no price
+	if pole.IngredientCost != 620 || pole.Profit != -120 || pole.LevelBand != "11-20" {
+		t.Errorf("Unexpected pole row %+v", pole)
+	}
+	if !reflect.DeepEqual(pole.MissingPrices, []string{"Rare Sap"}) {
+		t.Errorf("Expected Rare Sap to be missing, but got %v", pole.MissingPrices)
+	}
+}
+
+func TestCalculateWithoutPrices(t *testing.T) {
+	recipes := []recipe.CraftingRecipe{{
+		Name:          "Woodworking-7-Ash Lumber-From-1-Ash Log",
+		Result:        "Ash Lumber",
+		MainCraft:     "Woodworking",
+		SkillLevels:   map[string]int{"Woodworking": 7},
+		RequiredItems: []recipe.Item{{Name: "Ash Log", Count: 1}},
+	}}
+
+	rows := Calculate(recipes, item.NewCatalog(), nil, Options{})
+	if len(rows) != 1 || !reflect.DeepEqual(rows[0].MissingPrices, []string{"Ash Log", "Ash Lumber"}) {
+		t.Errorf("Expected every price to be missing, but got %+v", rows)
+	}
+}
+
+func TestParseHQRates(t *testing.T) {
+	testCases := []struct {
+		list     string
+		expected map[int]float64
+		err      bool
+	}{
+		{"1:0.05,2:0.01,3:0.005", map[int]float64{1: 0.05, 2: 0.01, 3: 0.005}, false},
+		{" 1:0.2 ", map[int]float64{1: 0.2}, false},
+		// No HQ results at all
+		{"", map[int]float64{}, false},
+		{"1=0.05", nil, true},
+		{"0:0.05", nil, true},
+		{"1:5", nil, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.list, func(t *testing.T) {
+			rates, err := ParseHQRates(tc.list)
+			if (err != nil) != tc.err {
+				t.Fatalf("Expected error %v, but got %v", tc.err, err)
+			}
+			if !tc.err && !reflect.DeepEqual(rates, tc.expected) {
+				t.Errorf("Expected %v, but got %v", tc.expected, rates)
+			}
+		})
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	rows := []Row{{Craft: "Cooking", Level: 5, LevelBand: "1-10", Recipe: "Cooking-5-Orange Juice-From-4-Saruta Orange, 1-Water Crystal", Result: "Orange Juice"}}
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, rows); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	expected := `Cooking,5,1-10,"Cooking-5-Orange Juice-From-4-Saruta Orange, 1-Water Crystal",Orange Juice,0.00,0.00,0.00,0.00,`
+	if len(lines) != 2 || lines[1] != expected {
+		t.Errorf("Expected %s, but got %v", expected, lines)
+	}
+}
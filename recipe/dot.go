@@ -0,0 +1,168 @@
+package recipe
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Ways an item is obtained, used to style graph nodes.
+const (
+	ObtainedCrafted  = "crafted"
+	ObtainedVendor   = "vendor"
+	ObtainedDrop     = "drop"
+	ObtainedGathered = "gathered"
+)
+
+// nodeStyles are the DOT node attributes of each way of obtaining an item, in
+// the order they win when an item is obtained several ways.
+var nodeStyles = []struct {
+	obtained string
+	style    string
+}{
+	{ObtainedVendor, `style=filled, fillcolor="#b7e1a1"`},
+	{ObtainedGathered, `style=filled, fillcolor="#f3d9a4"`},
+	{ObtainedDrop, `style=filled, fillcolor="#f4b6b6"`},
+	{ObtainedCrafted, `style=filled, fillcolor="#b6cff4"`},
+}
+
+// GraphOptions selects the recipes of a dependency graph. With neither
+// Target nor Craft set every recipe is drawn.
+type GraphOptions struct {
+	// Target draws the recipes making an item and, recursively, its ingredients.
+	Target string
+	// Craft draws the recipes of a main craft and, recursively, their ingredients.
+	Craft string
+	// ObtainedBy returns the other ways an item is obtained, e.g. vendor or
+	// drop. Crafted is known from the recipes. May be nil.
+	ObtainedBy func(name string) []string
+}
+
+// WriteDOT writes a Graphviz graph of recipe dependencies. Edges run from an
+// ingredient to the result it is used in, labelled with the count and the
+// recipe crystal. Crafted items are boxes, nodes are colored by how the item
+// is obtained and items with no known source are dashed.
+func WriteDOT(w io.Writer, recipes []CraftingRecipe, opts GraphOptions) error {
+	byResult := make(map[string][]CraftingRecipe)
+	for _, r := range recipes {
+		byResult[nameKey(r.Result)] = append(byResult[nameKey(r.Result)], r)
+	}
+
+	var selected []CraftingRecipe
+	seen := make(map[string]bool)
+	var visit func(name string)
+	visit = func(name string) {
+		if seen[nameKey(name)] {
+			return
+		}
+		seen[nameKey(name)] = true
+		for _, r := range byResult[nameKey(name)] {
+			selected = append(selected, r)
+			for _, ingredient := range r.RequiredItems {
+				visit(ingredient.Name)
+			}
+		}
+	}
+	switch {
+	case opts.Target != "":
+		visit(opts.Target)
+		if len(selected) == 0 {
+			return fmt.Errorf("no recipe makes %s", opts.Target)
+		}
+	case opts.Craft != "":
+		for _, r := range recipes {
+			if strings.EqualFold(r.MainCraft, opts.Craft) {
+				visit(r.Result)
+			}
+		}
+		if len(selected) == 0 {
+			return fmt.Errorf("no %s recipes", opts.Craft)
+		}
+	default:
+		for _, r := range recipes {
+			visit(r.Result)
+		}
+	}
+
+	out := bufio.NewWriter(w)
+	fmt.Fprintln(out, "digraph recipes {")
+	fmt.Fprintln(out, "  rankdir=LR;")
+	fmt.Fprintln(out, `  node [fontname="Helvetica", shape=ellipse];`)
+	fmt.Fprintln(out, `  edge [fontname="Helvetica", fontsize=10];`)
+
+	// Nodes, each spelling once, in name order
+	names := make(map[string]string)
+	for _, r := range selected {
+		names[nameKey(r.Result)] = r.Result
+		for _, ingredient := range r.RequiredItems {
+			if _, ok := names[nameKey(ingredient.Name)]; !ok {
+				names[nameKey(ingredient.Name)] = ingredient.Name
+			}
+		}
+	}
+	var keys []string
+	for key := range names {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		var obtained []string
+		if len(byResult[key]) > 0 {
+			obtained = append(obtained, ObtainedCrafted)
+		}
+		if opts.ObtainedBy != nil {
+			obtained = append(obtained, opts.ObtainedBy(names[key])...)
+		}
+		fmt.Fprintf(out, "  %s [%s];\n", dotQuote(names[key]), nodeAttributes(names[key], obtained))
+	}
+
+	for _, r := range selected {
+		for _, ingredient := range r.RequiredItems {
+			label := fmt.Sprintf("x%d", ingredient.Count)
+			if r.Crystal != "" {
+				label += ", " + r.Crystal
+			}
+			fmt.Fprintf(out, "  %s -> %s [label=%s, tooltip=%s];\n",
+				dotQuote(names[nameKey(ingredient.Name)]), dotQuote(names[nameKey(r.Result)]), dotQuote(label), dotQuote(r.Name))
+		}
+	}
+
+	fmt.Fprintln(out, "}")
+	return out.Flush()
+}
+
+func nodeAttributes(name string, obtained []string) string {
+	if len(obtained) == 0 {
+		return fmt.Sprintf(`label=%s, style=dashed, color=gray`, dotQuote(name))
+	}
+
+	has := make(map[string]bool)
+	for _, way := range obtained {
+		has[way] = true
+	}
+	var ways []string
+	attributes := ""
+	for _, nodeStyle := range nodeStyles {
+		if has[nodeStyle.obtained] {
+			ways = append(ways, nodeStyle.obtained)
+			if attributes == "" {
+				attributes = nodeStyle.style
+			}
+		}
+	}
+	if has[ObtainedCrafted] {
+		attributes += ", shape=box"
+	}
+	return fmt.Sprintf("label=%s, %s", dotQuote(name+"\n("+strings.Join(ways, ", ")+")"), attributes)
+}
+
+// nameKey compares item names ignoring case and underscores.
+func nameKey(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(strings.ReplaceAll(name, "_", " ")), " "))
+}
+
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
@@ -0,0 +1,52 @@
+package recipe
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteDOT(t *testing.T) {
+	recipes := []CraftingRecipe{
+		{Name: "Woodworking-7-Ash Lumber-From-1-Ash Log", Result: "Ash Lumber", Crystal: "Wind", MainCraft: "Woodworking",
+			RequiredItems: []Item{{Name: "Ash Log", Count: 1}}},
+		{Name: "Woodworking-12-Ash Pole-From-2-Ash Lumber", Result: "Ash Pole", Crystal: "Wind", MainCraft: "Woodworking",
+			RequiredItems: []Item{{Name: "Ash Lumber", Count: 2}, {Name: "Rare Sap", Count: 1}}},
+		{Name: "Smithing-1-Bronze Ingot-From-4-Copper Ore", Result: "Bronze Ingot", Crystal: "Fire", MainCraft: "Smithing",
+			RequiredItems: []Item{{Name: "Copper Ore", Count: 4}}},
+	}
+	obtainedBy := func(name string) []string {
+		switch name {
+		case "Ash Log":
+			return []string{ObtainedGathered, ObtainedVendor}
+		}
+		return nil
+	}
+
+	var buf bytes.Buffer
+	err := WriteDOT(&buf, recipes, GraphOptions{Target: "ash pole", ObtainedBy: obtainedBy})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dot := buf.String()
+
+	expected := []string{
+		`"Ash Log" [label="Ash Log\n(vendor, gathered)", style=filled, fillcolor="#b7e1a1"];`,
+		`"Ash Lumber" [label="Ash Lumber\n(crafted)", style=filled, fillcolor="#b6cff4", shape=box];`,
+		`"Rare Sap" [label="Rare Sap", style=dashed, color=gray];`,
+		`"Ash Lumber" -> "Ash Pole" [label="x2, Wind", tooltip="Woodworking-12-Ash Pole-From-2-Ash Lumber"];`,
+		`"Ash Log" -> "Ash Lumber" [label="x1, Wind"`,
+	}
+	for _, line := range expected {
+		if !strings.Contains(dot, line) {
+			t.Errorf("Expected %s in\n%s", line, dot)
+		}
+	}
+	if strings.Contains(dot, "Bronze Ingot") {
+		t.Errorf("Expected only the Ash Pole chain, but got\n%s", dot)
+	}
+
+	if err := WriteDOT(&buf, recipes, GraphOptions{Target: "Rare Sap"}); err == nil {
+		t.Errorf("Expected an error for an item no recipe makes")
+	}
+}
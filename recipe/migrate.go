@@ -0,0 +1,79 @@
+package recipe
+
+import (
+	"encoding/json"
+	"ffxi/transform"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Upgrade decodes a recipe written by any version of the recipes command and
+// brings it up to transform.SchemaVersion, deriving the fields older files
+// lack. The returned notes name what could not be derived.
+func Upgrade(data []byte) (CraftingRecipe, []string, error) {
+	var r CraftingRecipe
+	if err := json.Unmarshal(data, &r); err != nil {
+		return r, nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return r, nil, err
+	}
+	if r.SchemaVersion >= transform.SchemaVersion {
+		return r, nil, nil
+	}
+
+	var notes []string
+	if r.MainCraft == "" {
+		craft, tied := highestSkill(r.SkillLevels)
+		r.MainCraft = craft
+		switch {
+		case craft == "":
+			notes = append(notes, "MainCraft: no SkillLevels to derive it from")
+		case tied:
+			notes = append(notes, fmt.Sprintf("MainCraft: several crafts share the highest level, picked %s", craft))
+		}
+	}
+
+	// Version 1 names like "Ash Lumber-From-1Ash Log" lack the skills and
+	// the count separators. The part before -From- is the scraped recipe
+	// name, which keeps the result count.
+	recipeName := r.Result
+	if i := strings.Index(r.Name, "-From-"); i > 0 {
+		recipeName = r.Name[:i]
+	}
+	if r.MainCraft != "" && !strings.HasPrefix(r.Name, r.MainCraft+"-") {
+		r.Name = determineCraftName(r.SkillLevels, r.RequiredItems, recipeName)
+	}
+
+	if _, ok := fields["AllPossibleResults"]; !ok {
+		r.AllPossibleResults = []ResultsIncludingHighQuality{{Name: r.Result, Count: extractRecipeQuantity(recipeName)}}
+		notes = append(notes, "AllPossibleResults: HQ results are not in the file, only the NQ result was derived")
+	}
+	if _, ok := fields["RequiredTools"]; !ok {
+		notes = append(notes, "RequiredTools: not in the file, left empty")
+	}
+
+	r.SchemaVersion = transform.SchemaVersion
+	return r, notes, nil
+}
+
+// highestSkill returns the craft with the highest level, the first by name
+// when several share it.
+func highestSkill(skillLevels map[string]int) (craft string, tied bool) {
+	var crafts []string
+	for c := range skillLevels {
+		crafts = append(crafts, c)
+	}
+	sort.Strings(crafts)
+	for _, c := range crafts {
+		switch {
+		case craft == "" || skillLevels[c] > skillLevels[craft]:
+			craft, tied = c, false
+		case skillLevels[c] == skillLevels[craft]:
+			tied = true
+		}
+	}
+	return craft, tied
+}
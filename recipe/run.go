@@ -0,0 +1,126 @@
+package recipe
+
+import (
+	"encoding/json"
+	"ffxi/transform"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Run transforms the recipe scrape inputs into one <recipe> file per
+// recipe in the output directory, and writes all_craft.json and
+// item_names.txt of every recipe into summaryDir.
+func Run(opts transform.Options, summaryDir string) error {
+	if err := opts.CheckFormat(transform.Formats...); err != nil {
+		return err
+	}
+	Verbose = opts.Verbose
+
+	inputFiles, err := opts.InputFiles()
+	if err != nil {
+		return err
+	}
+	if len(inputFiles) == 0 {
+		return fmt.Errorf("no recipe inputs given")
+	}
+
+	now := transform.Now()
+	var craftingRecipes []CraftingRecipe
+	for _, inputFile := range inputFiles {
+		inputJSON, err := opts.ReadInput(inputFile)
+		if err != nil {
+			return err
+		}
+
+		fileRecipes, err := TransformRecipes(string(inputJSON))
+		if err != nil {
+			return fmt.Errorf("%s: %v", inputFile, err)
+		}
+		for _, r := range fileRecipes {
+			r.Provenance.Stamp(inputFile, now)
+		}
+		craftingRecipes = append(craftingRecipes, fileRecipes...)
+	}
+
+	for _, recipe := range craftingRecipes {
+		fileName := ShortFileName(recipe.Name)
+		opts.Logf("Resulting filename: %s", fileName)
+
+		err = opts.Write(fileName, recipe, func() transform.Table { return IngredientTable([]CraftingRecipe{recipe}) })
+		if err != nil {
+			return err
+		}
+	}
+
+	// Marshal the CraftingRecipe slice into JSON
+	outputJSON, err := json.MarshalIndent(craftingRecipes, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(filepath.Join(summaryDir, "all_craft.json"), outputJSON, 0644)
+	if err != nil {
+		return err
+	}
+
+	// Write item names to a file
+	return os.WriteFile(filepath.Join(summaryDir, "item_names.txt"), []byte(strings.Join(ItemNames(craftingRecipes), "\n")), 0644)
+}
+
+// IngredientTable flattens recipes into one row per ingredient.
+func IngredientTable(recipes []CraftingRecipe) transform.Table {
+	table := transform.Table{Columns: []string{"Recipe", "Result", "MainCraft", "Level", "Crystal", "Ingredient", "Count"}}
+	for _, r := range recipes {
+		for _, ingredient := range r.RequiredItems {
+			table.Rows = append(table.Rows, []string{
+				r.Name,
+				r.Result,
+				r.MainCraft,
+				strconv.Itoa(r.SkillLevels[r.MainCraft]),
+				r.Crystal,
+				ingredient.Name,
+				strconv.Itoa(ingredient.Count),
+			})
+		}
+	}
+	return table
+}
+
+// ItemNames lists every distinct ingredient of recipes in first seen order.
+func ItemNames(recipes []CraftingRecipe) []string {
+	var items []string
+	uniqueItems := make(map[string]struct{})
+
+	for _, r := range recipes {
+		for _, i := range r.RequiredItems {
+			if _, ok := uniqueItems[i.Name]; !ok {
+				items = append(items, i.Name)
+				// Add the item name to the map
+				uniqueItems[i.Name] = struct{}{}
+			}
+		}
+	}
+
+	return items
+}
+
+// ShortFileName is the file name of a recipe, its name up to the first
+// ingredient separator.
+func ShortFileName(recipeName string) string {
+	// Extract everything before the first ","
+	index := strings.Index(recipeName, ",")
+	if index == -1 {
+		// file name is probably pretty short
+		return recipeName
+	}
+
+	resultFilename := strings.TrimSpace(recipeName[:index])
+	if resultFilename == "" {
+		return recipeName
+	}
+
+	return resultFilename
+}
@@ -2,6 +2,7 @@ package recipe
 
 import (
 	"encoding/json"
+	"ffxi/transform"
 	"fmt"
 	"regexp"
 	"sort"
@@ -9,6 +10,15 @@ import (
 	"strings"
 )
 
+// Verbose turns on the parser's debug output.
+var Verbose bool
+
+func debugf(format string, args ...interface{}) {
+	if Verbose {
+		fmt.Printf(format, args...)
+	}
+}
+
 // TransformRecipes processes the input JSON and returns the resulting JSON string.
 func TransformRecipes(inputJSON string) ([]CraftingRecipe, error) {
 	// Unmarshal the entire JSON data
@@ -20,7 +30,7 @@ func TransformRecipes(inputJSON string) ([]CraftingRecipe, error) {
 
 	// Create a slice of CraftingRecipe objects
 	var craftingRecipes []CraftingRecipe
-	for _, recipe := range recipes {
+	for i, recipe := range recipes {
 		// Extract craft type from the "Text" field using regex
 
 		craftType := extractCraftType(recipe.Text)
@@ -45,6 +55,7 @@ func TransformRecipes(inputJSON string) ([]CraftingRecipe, error) {
 
 		// Create CraftingRecipe object
 		craftingRecipes = append(craftingRecipes, CraftingRecipe{
+			SchemaVersion:      transform.SchemaVersion,
 			Result:             recipe.RecipeItem,
 			Crystal:            recipe.Crystal,
 			MainCraft:          realMainCraftType,
@@ -53,6 +64,7 @@ func TransformRecipes(inputJSON string) ([]CraftingRecipe, error) {
 			Name:               name,
 			AllPossibleResults: allResults,
 			RequiredTools:      requiredTools,
+			Provenance:         recipeProvenance(i, recipe),
 		})
 	}
 
@@ -60,6 +72,18 @@ func TransformRecipes(inputJSON string) ([]CraftingRecipe, error) {
 
 }
 
+// recipeProvenance traces a recipe to its scrape row, the result's itemdb
+// page and the links of the HQ results.
+func recipeProvenance(row int, recipe CraftData) *transform.Provenance {
+	provenance := &transform.Provenance{Row: row, SourceURL: recipe.GuildRecipesWoodworkingURL}
+	for _, link := range []string{recipe.Something, recipe.Ingredient2Link, recipe.Ingredient3Link, recipe.Ingredient4Link} {
+		if link != "" {
+			provenance.Links = append(provenance.Links, link)
+		}
+	}
+	return provenance
+}
+
 func extractRecipeQuantity(itemName string) int {
 	re := regexp.MustCompile(` x(\d+)$`)
 	match := re.FindStringSubmatch(itemName)
@@ -182,12 +206,12 @@ func extractHighQualityResults(ingredients string) ([]ResultsIncludingHighQualit
 	itemLines := strings.Split(ingredients, "\n")
 
 	for _, line := range itemLines {
-		fmt.Printf("line: %s\n", line)
+		debugf("line: %s\n", line)
 		// Use the updated regular expression to capture item details
 		re := regexp.MustCompile(`HQ(\d+): (.*?)(?: x(\d+))?$`)
 
 		match := re.FindStringSubmatch(line)
-		fmt.Printf("match: %v\n", strings.Join(match, ", "))
+		debugf("match: %v\n", strings.Join(match, ", "))
 		if len(match) > 0 {
 			hqLevel, _ := strconv.Atoi(match[1])
 			itemName := match[2]
@@ -197,8 +221,8 @@ func extractHighQualityResults(ingredients string) ([]ResultsIncludingHighQualit
 				quantity, _ = strconv.Atoi(match[3])
 			}
 
-			fmt.Printf("itemName: %s\n", itemName)
-			fmt.Printf("itemQuantity: %s\n", match[3])
+			debugf("itemName: %s\n", itemName)
+			debugf("itemQuantity: %s\n", match[3])
 
 			results = append(results, ResultsIncludingHighQuality{
 				Name:             itemName,
@@ -221,7 +245,7 @@ func determineCraftName(skillLevels map[string]int, requiredItems []Item, result
 	for _, craftType := range craftTypes {
 		level := skillLevels[craftType]
 		skills = append(skills, fmt.Sprintf("%s-%d", craftType, level))
-		fmt.Printf("%s-%d\n", craftType, level)
+		debugf("%s-%d\n", craftType, level)
 	}
 
 	var items []string
@@ -261,6 +285,7 @@ func extractToolRequirement(text string) string {
 
 // CraftingRecipe represents the data extracted for each craft.
 type CraftingRecipe struct {
+	SchemaVersion      int                           `json:"SchemaVersion"`
 	Crystal            string                        `json:"Crystal"`
 	RequiredItems      []Item                        `json:"RequiredItems"`
 	SkillLevels        map[string]int                `json:"SkillLevels"`
@@ -269,6 +294,7 @@ type CraftingRecipe struct {
 	MainCraft          string                        `json:"MainCraft"`
 	AllPossibleResults []ResultsIncludingHighQuality `json:"AllPossibleResults"`
 	RequiredTools      string                        `json:"RequiredTools"`
+	Provenance         *transform.Provenance         `json:"Provenance,omitempty"`
 }
 
 // CrystalData represents the data extracted for each crystal.
@@ -1,6 +1,7 @@
 package recipe
 
 import (
+	"ffxi/transform"
 	"fmt"
 	"reflect"
 	"testing"
@@ -234,3 +235,26 @@ func TestExtractToolRequirement(t *testing.T) {
 //		}
 //	}
 //}
+
+func TestRecipeProvenance(t *testing.T) {
+	recipes, err := TransformRecipes(`[
+		{"Text": "Guild Recipes: Woodworking (Synthesis)", "recipe_name": "Ash Lumber", "Guild_Recipes_Woodworking_URL": "http://ffxi.somepage.com/itemdb/1",
+			"recipe_item": "Ash Lumber", "level_cap": "7", "crystal": "Wind", "synth_or_desynth": "Ash Log"},
+		{"Text": "Guild Recipes: Clothcraft (Synthesis)", "recipe_name": "Cotton Cape", "Guild_Recipes_Woodworking_URL": "http://ffxi.somepage.com/itemdb/1888",
+			"recipe_item": "Cotton Cape", "level_cap": "18", "crystal": "Earth", "synth_or_desynth": "Cotton Thread, Cotton Cloth x2",
+			"ingredients": "HQ1: Cotton Cape +1\n", "something": "http://ffxi.somepage.com/itemdb/1889"}
+	]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []*transform.Provenance{
+		{Row: 0, SourceURL: "http://ffxi.somepage.com/itemdb/1"},
+		{Row: 1, SourceURL: "http://ffxi.somepage.com/itemdb/1888", Links: []string{"http://ffxi.somepage.com/itemdb/1889"}},
+	}
+	for i, r := range recipes {
+		if !reflect.DeepEqual(r.Provenance, expected[i]) {
+			t.Errorf("Expected provenance %+v, but got %+v", expected[i], r.Provenance)
+		}
+	}
+}
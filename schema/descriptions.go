@@ -0,0 +1,115 @@
+package schema
+
+import (
+	"ffxi/harvestpoints"
+	"ffxi/mobdrops"
+	"reflect"
+	"time"
+)
+
+const versionDescription = "Output format version of the record, missing before version 2. Run ffxi migrate to upgrade older files."
+
+const provenanceDescription = "Scraper export row the record was built from, missing in files written before provenance was recorded."
+
+// descriptions document the output types by type and by type.field, keyed by
+// the reflect type name like mobdrops.ItemDrop.
+var descriptions = map[string]string{
+	"recipe.CraftingRecipe":                               "A guild synthesis recipe.",
+	"recipe.CraftingRecipe.SchemaVersion":                 versionDescription,
+	"recipe.CraftingRecipe.Crystal":                       "Element of the crystal the synthesis uses, e.g. Wind.",
+	"recipe.CraftingRecipe.RequiredItems":                 "Ingredients used up by the synthesis.",
+	"recipe.CraftingRecipe.SkillLevels":                   "Level cap of each craft the recipe needs, including MainCraft.",
+	"recipe.CraftingRecipe.Result":                        "Item the normal quality synthesis makes.",
+	"recipe.CraftingRecipe.Name":                          "Unique recipe name: <craft>-<level>-...-<result>-From-<count>-<ingredient>...",
+	"recipe.CraftingRecipe.MainCraft":                     "Craft of the guild the recipe is listed under.",
+	"recipe.CraftingRecipe.AllPossibleResults":            "Normal and high quality results with their counts.",
+	"recipe.CraftingRecipe.RequiredTools":                 "Other requirements such as a key item or furnishing, empty when there are none.",
+	"recipe.CraftingRecipe.Provenance":                    provenanceDescription,
+	"recipe.Item":                                         "An ingredient and how many of it are used.",
+	"recipe.ResultsIncludingHighQuality":                  "One possible result of a synthesis.",
+	"recipe.ResultsIncludingHighQuality.HighQualityLevel": "0 for the normal quality result, 1-3 for HQ1-HQ3.",
+
+	"merchants.MerchantInfo":                        "A merchant and the goods they sell.",
+	"merchants.MerchantInfo.SchemaVersion":          versionDescription,
+	"merchants.MerchantInfo.Zone":                   "Canonical zone ID, e.g. Port_Bastok.",
+	"merchants.MerchantInfo.Position":               "Map position of the merchant within the zone, e.g. F-10.",
+	"merchants.MerchantInfo.Provenance":             provenanceDescription,
+	"merchants.ItemInfo":                            "A good sold by a merchant.",
+	"merchants.ItemInfo.MinPrice":                   "Lowest price in gil, depending on fame and nation.",
+	"merchants.ItemInfo.MaxPrice":                   "Highest price in gil, equal to MinPrice for fixed prices.",
+	"merchants.ItemInfo.RankRequirement":            "Nation rank needed to buy the good, empty when there is none.",
+	"mobdrops.MobInfo":                              "A mob of a zone with its drops.",
+	"mobdrops.MobInfo.SchemaVersion":                versionDescription,
+	"mobdrops.MobInfo.LevelRange":                   "Level spread of the mob, null when unknown.",
+	"mobdrops.MobInfo.ZoneName":                     "Canonical zone ID, e.g. Valkurm_Dunes.",
+	"mobdrops.ItemDrop":                             "An item a mob drops.",
+	"mobdrops.ItemDrop.Percent":                     "Drop rate in percent, null when unknown. Never defaults to 100.",
+	"mobdrops.ItemDrop.Source":                      "Where Percent came from.",
+	"mobdrops.ItemDrop.SourceZone":                  "Zone the rate was taken from when Source is other_zone.",
+	"mobdrops.ItemDrop.AmountDropped":               "Number of drops observed by the scrape.",
+	"mobdrops.ItemDrop.AmountDefeated":              "Number of kills observed by the scrape.",
+	"mobdrops.ItemDrop.Batches":                     "Scrape batches the rate was merged from.",
+	"mobdrops.ItemDrop.RateConflict":                "Set when the merged batches disagree on the rate.",
+	"mobdrops.ItemDrop.Provenance":                  "Scrape row the rate was taken from, the first batch's for merged rates.",
+	"mobdrops.ItemInfo":                             "A merged drop scrape row.",
+	"mobdrops.ItemInfo.SchemaVersion":               versionDescription,
+	"mobdrops.ItemInfo.Count":                       "Drops out of kills as scraped, e.g. 652/1665.",
+	"mobdrops.ItemInfo.Chance":                      "Drop rate as scraped, e.g. 39.2%.",
+	"mobdrops.ItemInfo.NPCURL":                      "Page of the mob the row is about.",
+	"mobdrops.ItemInfo.PageURL":                     "Page the row was scraped from.",
+	"mobdrops.ItemInfo.Batches":                     "Counts of each scrape batch the row was merged from.",
+	"mobdrops.ItemInfo.Conflict":                    "Set when the batches disagree on the rate.",
+	"mobdrops.ItemInfo.Provenance":                  "Scrape row, the first batch's for merged rows.",
+	"mobdrops.BatchCount.Provenance":                "Scrape row of the batch.",
+	"harvestpoints.HarvestPoint":                    "A gathering point of a zone with its yields.",
+	"harvestpoints.HarvestPoint.SchemaVersion":      versionDescription,
+	"harvestpoints.HarvestPoint.Name":               "Display name of the point, e.g. Harvesting Point.",
+	"harvestpoints.HarvestPoint.RequiredTool":       "Tool used up at the point, e.g. Sickle.",
+	"harvestpoints.HarvestPoint.ItemDrops":          "Unused, kept for the mob file shape.",
+	"harvestpoints.HarvestPoint.ItemDropInfos":      "Items the point yields.",
+	"harvestpoints.HarvestPoint.TotalKnownDefeated": "Synthetic number of attempts TotalKnownDrops are out of.",
+	"harvestpoints.ItemDropInfo":                    "An item a gathering point yields.",
+	"harvestpoints.ItemDropInfo.FriendlyName":       "Item name with underscores, e.g. Grain_Seeds.",
+	"harvestpoints.ItemDropInfo.TotalKnownDrops":    "Rate rounded to a count out of TotalKnownDefeated.",
+	"harvestpoints.ItemDropInfo.Percent":            "Exact source rate in percent, only in the exact view.",
+	"harvestpoints.ItemDropInfo.Tier":               "Abundance tier, e.g. Very_Rare, only in the exact view.",
+	"harvestpoints.ItemDropInfo.Provenance":         provenanceDescription,
+
+	"transform.Provenance":               "Where a record came from, to trace a value back to its source row.",
+	"transform.Provenance.File":          "Name of the scraper export, e.g. all_mobs_nineth.json.",
+	"transform.Provenance.Row":           "Index of the row in File, counting from 0. Harvest inputs count within a zone's item list.",
+	"transform.Provenance.SourceURL":     "Page the row was scraped from, e.g. the itemdb page of a recipe result.",
+	"transform.Provenance.NPCURL":        "Page of the mob the row is about.",
+	"transform.Provenance.Links":         "Other itemdb links of the row, e.g. of HQ results.",
+	"transform.Provenance.TransformedAt": "When the transformer ran, in RFC 3339.",
+}
+
+// formats give string types their JSON Schema format.
+var formats = map[reflect.Type]string{
+	reflect.TypeOf(time.Time{}): "date-time",
+}
+
+// enums restrict string types to their known values.
+var enums = map[reflect.Type][]string{
+	reflect.TypeOf(harvestpoints.PointType("")): {
+		string(harvestpoints.Harvesting), string(harvestpoints.Logging), string(harvestpoints.Mining),
+		string(harvestpoints.Excavation), string(harvestpoints.Clamming), string(harvestpoints.Fishing),
+	},
+}
+
+// scrapeColumns are optional properties of struct types, keyed like
+// descriptions, for scrape columns the Go types read past. They let scrape
+// batches validate as the rows they are.
+var scrapeColumns = map[string]map[string]string{
+	"mobdrops.ItemInfo": {
+		"Count1":       "Unused scrape column holding the kill count of Count.",
+		"Original_URL": "Page the scraper was started from.",
+		"Zone_URL":     "Page of the zone.",
+	},
+}
+
+// fieldEnums restrict string fields to their known values, keyed like
+// descriptions.
+var fieldEnums = map[string][]string{
+	"mobdrops.ItemDrop.Source": {mobdrops.SourceScrape, mobdrops.SourceOtherZone, mobdrops.SourceUnknown},
+}
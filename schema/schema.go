@@ -0,0 +1,197 @@
+// Package schema generates JSON Schemas for the transformer outputs from the
+// Go types and validates output files against them.
+package schema
+
+import (
+	"ffxi/harvestpoints"
+	"ffxi/merchants"
+	"ffxi/mobdrops"
+	"ffxi/recipe"
+	"ffxi/transform"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Version is the schema version of the output formats, transform.SchemaVersion.
+// It is part of every schema $id, so consumers can pin the version they were
+// written against.
+const Version = transform.SchemaVersion
+
+// Draft is the JSON Schema dialect of the generated schemas.
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is the subset of JSON Schema the generator produces. Type is a
+// string or a list of strings, AdditionalProperties false or a *Schema.
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	ID                   string             `json:"$id,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 interface{}        `json:"type,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty"`
+}
+
+// Kind is an output format with the Go type its files hold.
+type Kind struct {
+	Name        string
+	Title       string
+	Description string
+	Type        reflect.Type
+	// OneOrList allows a single record as well as a list of them.
+	OneOrList bool
+}
+
+// Kinds are the transformer output formats.
+var Kinds = []Kind{
+	{"recipe", "Crafting recipe", "A recipe file, or a list of recipes like all_craft.json, written by the recipes command.",
+		reflect.TypeOf(recipe.CraftingRecipe{}), true},
+	{"merchants", "Zone merchants", "The merchants of one zone with their goods, written by the merchants command.",
+		reflect.TypeOf([]merchants.MerchantInfo{}), false},
+	{"drops", "Zone mob drops", "The mobs of one zone with their drop rates, written by the drops command as <zone>_output.json.",
+		reflect.TypeOf([]mobdrops.MobInfo{}), false},
+	{"drop-scrape", "Drop scrape", "Drop scrape rows, either an all_mobs_*.json batch or the merge the drops command writes as merged_drops.json.",
+		reflect.TypeOf([]mobdrops.ItemInfo{}), false},
+	{"harvest", "Zone gathering points", "The gathering points of one zone with their yields, written by the harvest command.",
+		reflect.TypeOf([]harvestpoints.HarvestPoint{}), false},
+}
+
+// Lookup returns the kind with the given name.
+func Lookup(name string) (Kind, bool) {
+	for _, kind := range Kinds {
+		if kind.Name == name {
+			return kind, true
+		}
+	}
+	return Kind{}, false
+}
+
+// ID returns the $id of a kind's schema, e.g. ffxi/recipe/v2.
+func (k Kind) ID() string {
+	return fmt.Sprintf("ffxi/%s/v%d", k.Name, Version)
+}
+
+// Schema generates the schema document of a kind.
+func (k Kind) Schema() *Schema {
+	s := Generate(k.Type)
+	if k.OneOrList {
+		s = &Schema{OneOf: []*Schema{s, {Type: "array", Items: s}}}
+	}
+	s.Schema = Draft
+	s.ID = k.ID()
+	s.Title = k.Title
+	s.Description = k.Description
+	return s
+}
+
+// Generate returns the schema of a Go type as encoding/json writes it. Nil
+// pointers, slices and maps are written as null, so their schemas allow it.
+// Structs don't allow unknown properties so drifting files are caught.
+func Generate(t reflect.Type) *Schema {
+	if enum, ok := enums[t]; ok {
+		return &Schema{Type: "string", Enum: enum}
+	}
+	if format, ok := formats[t]; ok {
+		return &Schema{Type: "string", Format: format}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		s := Generate(t.Elem())
+		s.Type = nullable(s.Type)
+		return s
+	case reflect.Struct:
+		return generateStruct(t)
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: []string{"array", "null"}, Items: Generate(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: []string{"object", "null"}, AdditionalProperties: Generate(t.Elem())}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	}
+	// Interfaces can hold anything
+	return &Schema{}
+}
+
+func generateStruct(t reflect.Type) *Schema {
+	s := &Schema{
+		Type:                 "object",
+		Description:          descriptions[t.String()],
+		Properties:           make(map[string]*Schema),
+		AdditionalProperties: false,
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name, omitEmpty, skip := jsonName(field)
+		if skip {
+			continue
+		}
+
+		property := Generate(field.Type)
+		key := t.String() + "." + field.Name
+		if description, ok := descriptions[key]; ok {
+			property.Description = description
+		}
+		if enum, ok := fieldEnums[key]; ok {
+			property.Enum = enum
+		}
+		s.Properties[name] = property
+		if !omitEmpty {
+			s.Required = append(s.Required, name)
+		}
+	}
+	for name, description := range scrapeColumns[t.String()] {
+		s.Properties[name] = &Schema{Type: "string", Description: description}
+	}
+	return s
+}
+
+// jsonName returns the property name of a field as encoding/json sees it.
+func jsonName(field reflect.StructField) (name string, omitEmpty, skip bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false, true
+	}
+	parts := strings.Split(tag, ",")
+	name = parts[0]
+	if name == "" {
+		name = field.Name
+	}
+	for _, option := range parts[1:] {
+		if option == "omitempty" {
+			omitEmpty = true
+		}
+	}
+	return name, omitEmpty, false
+}
+
+func nullable(t interface{}) interface{} {
+	switch t := t.(type) {
+	case string:
+		return []string{t, "null"}
+	case []string:
+		for _, name := range t {
+			if name == "null" {
+				return t
+			}
+		}
+		return append(t, "null")
+	}
+	return t
+}
@@ -0,0 +1,141 @@
+package schema
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	kind, _ := Lookup("drops")
+	s := kind.Schema()
+	if s.ID != "ffxi/drops/v2" {
+		t.Errorf("Expected ID ffxi/drops/v2, but got %s", s.ID)
+	}
+
+	drop := s.Items.Properties["ItemDrops"].Items
+	testCases := []struct {
+		property string
+		expected interface{}
+	}{
+		{"Percent", []string{"number", "null"}},
+		{"AmountDropped", "integer"},
+		{"Batches", []string{"array", "null"}},
+	}
+	for _, tc := range testCases {
+		if !reflect.DeepEqual(drop.Properties[tc.property].Type, tc.expected) {
+			t.Errorf("Expected %s to be %v, but got %v", tc.property, tc.expected, drop.Properties[tc.property].Type)
+		}
+	}
+	if !reflect.DeepEqual(drop.Required, []string{"Name", "Percent", "Source", "AmountDropped", "AmountDefeated"}) {
+		t.Errorf("Expected the omitempty fields to be optional, but got %v", drop.Required)
+	}
+	if drop.Properties["Percent"].Description == "" {
+		t.Errorf("Expected Percent to be described")
+	}
+
+	// Every kind must marshal to a JSON document
+	for _, kind := range Kinds {
+		if _, err := json.Marshal(kind.Schema()); err != nil {
+			t.Errorf("%s: %v", kind.Name, err)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		kind     string
+		input    string
+		expected []string
+	}{
+		{
+			name:  "current recipe",
+			kind:  "recipe",
+			input: `{"SchemaVersion": 2, "Crystal": "Wind", "RequiredItems": [{"Name": "Ash Log", "Count": 1}], "SkillLevels": {"Woodworking": 7}, "Result": "Ash Lumber", "Name": "Woodworking-7-Ash Lumber-From-1-Ash Log", "MainCraft": "Woodworking", "AllPossibleResults": [], "RequiredTools": ""}`,
+		},
+		{
+			name:     "old recipe shape",
+			kind:     "recipe",
+			input:    `[{"Crystal": "Wind Crystal", "RequiredItems": [{"Name": "Ash Log", "Count": 1}], "SkillLevels": {"Woodworking": 8}, "Result": "Ash Lumber", "Name": "Ash Lumber-From-1Ash Log"}]`,
+			expected: []string{"$[0]: missing property SchemaVersion", "$[0]: missing property MainCraft", "$[0]: missing property AllPossibleResults", "$[0]: missing property RequiredTools"},
+		},
+		{
+			name:     "merchant price as text",
+			kind:     "merchants",
+			input:    `[{"SchemaVersion": 2, "Name": "Dahjal", "Zone": "Port_Bastok", "Items": [{"Name": "Ash Log", "MinPrice": "90", "MaxPrice": 110, "RankRequirement": ""}]}]`,
+			expected: []string{"$[0].Items[0].MinPrice: expected integer, got string"},
+		},
+		{
+			name:     "unknown drop source",
+			kind:     "drops",
+			input:    `[{"SchemaVersion": 2, "Name": "Bogy", "LevelRange": null, "ZoneName": "Valkurm_Dunes", "ItemDrops": [{"Name": "bloody robe", "Percent": 39.2, "Source": "guess", "AmountDropped": 0, "AmountDefeated": 0}]}]`,
+			expected: []string{`$[0].ItemDrops[0].Source: "guess" is not one of scrape, other_zone, unknown`},
+		},
+		{
+			name:     "merchant provenance time",
+			kind:     "merchants",
+			input:    `[{"SchemaVersion": 2, "Name": "Dahjal", "Zone": "Port_Bastok", "Items": [], "Provenance": {"File": "input.json", "Row": 0, "TransformedAt": "yesterday"}}]`,
+			expected: []string{`$[0].Provenance.TransformedAt: "yesterday" is not an RFC 3339 date-time`},
+		},
+		{
+			name:  "drop scrape batch",
+			kind:  "drop-scrape",
+			input: `[{"ItemName": "Crab Apron", "NPC_URL": "", "NPC": "Snipper", "Zone": "Valkurm_Dunes", "Count": "5291 out of 53763", "Count1": " out of 53763", "Chance": "9.8%", "Page_URL": "", "Original_URL": "", "Zone_URL": ""}]`,
+		},
+		{
+			name:     "harvest without point type",
+			kind:     "harvest",
+			input:    `[{"SchemaVersion": 2, "Name": "Harvesting Point", "LevelRange": null, "ZoneName": "Giddeus", "ItemDrops": [], "ItemDropInfos": [], "TotalKnownDefeated": 100, "Extra": 1}]`,
+			expected: []string{"$[0]: missing property PointType", "$[0]: missing property RequiredTool", "$[0]: unknown property Extra"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			kind, _ := Lookup(tc.kind)
+			issues, err := kind.Schema().Validate([]byte(tc.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var messages []string
+			for _, issue := range issues {
+				messages = append(messages, issue.Path+": "+issue.Message)
+			}
+			if !reflect.DeepEqual(messages, tc.expected) {
+				t.Errorf("Expected %q, but got %q", tc.expected, messages)
+			}
+		})
+	}
+}
+
+func TestValidateDir(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"Port_Bastok.json": `[{"SchemaVersion": 2, "Name": "Dahjal", "Zone": "Port_Bastok", "Items": [{"Name": "Ash Log", "MinPrice": 90, "MaxPrice": 110, "RankRequirement": ""}]}]`,
+		"sample.json":      `[{"Crystal": "Wind Crystal", "RequiredItems": [], "SkillLevels": {}, "Result": "Ash Lumber", "Name": "Ash Lumber-From-1Ash Log"}]`,
+		"notes.json":       `{"Todo": "more zones"}`,
+		"notes.txt":        `not json`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	report, err := ValidateDir(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Files != 3 || report.Invalid != 2 {
+		t.Errorf("Expected 2 of 3 files to be invalid, but got %+v", report)
+	}
+	for _, issue := range report.Issues {
+		if strings.HasSuffix(issue.File, "Port_Bastok.json") {
+			t.Errorf("Expected Port_Bastok.json to be valid, but got %+v", issue)
+		}
+	}
+}
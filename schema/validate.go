@@ -0,0 +1,251 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Issue is one place a file doesn't match its schema.
+type Issue struct {
+	File    string `json:"File"`
+	Kind    string `json:"Kind"`
+	Path    string `json:"Path"`
+	Message string `json:"Message"`
+}
+
+// Report is the machine readable result of ValidateDir.
+type Report struct {
+	Files   int     `json:"Files"`
+	Invalid int     `json:"Invalid"`
+	Issues  []Issue `json:"Issues"`
+}
+
+// Validate checks a JSON document against the schema and returns its issues
+// with JSON paths like $[0].Items[2].MinPrice.
+func (s *Schema) Validate(data []byte) ([]Issue, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil {
+		return nil, err
+	}
+	var issues []Issue
+	s.validate("$", v, &issues)
+	return issues, nil
+}
+
+func (s *Schema) validate(path string, v interface{}, issues *[]Issue) {
+	addIssue := func(format string, args ...interface{}) {
+		*issues = append(*issues, Issue{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if len(s.OneOf) > 0 {
+		// Report the issues of the closest alternative, preferring one of the
+		// right type
+		var best []Issue
+		bestTyped := false
+		for i, alternative := range s.OneOf {
+			var alternativeIssues []Issue
+			alternative.validate(path, v, &alternativeIssues)
+			if len(alternativeIssues) == 0 {
+				return
+			}
+			typed := hasType(typeNames(alternative.Type), v)
+			if i == 0 || typed && !bestTyped || typed == bestTyped && len(alternativeIssues) < len(best) {
+				best, bestTyped = alternativeIssues, typed
+			}
+		}
+		*issues = append(*issues, best...)
+		return
+	}
+
+	types := typeNames(s.Type)
+	if len(types) > 0 && !hasType(types, v) {
+		addIssue("expected %s, got %s", strings.Join(types, " or "), jsonType(v))
+		return
+	}
+	if len(s.Enum) > 0 {
+		if str, ok := v.(string); ok && !contains(s.Enum, str) {
+			addIssue("%q is not one of %s", str, strings.Join(s.Enum, ", "))
+		}
+	}
+	if s.Format == "date-time" {
+		if str, ok := v.(string); ok {
+			if _, err := time.Parse(time.RFC3339, str); err != nil {
+				addIssue("%q is not an RFC 3339 date-time", str)
+			}
+		}
+	}
+
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				addIssue("missing property %s", name)
+			}
+		}
+		var names []string
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if property, ok := s.Properties[name]; ok {
+				property.validate(path+"."+name, v[name], issues)
+				continue
+			}
+			switch additional := s.AdditionalProperties.(type) {
+			case bool:
+				if !additional {
+					addIssue("unknown property %s", name)
+				}
+			case *Schema:
+				additional.validate(path+"."+name, v[name], issues)
+			}
+		}
+	case []interface{}:
+		if s.Items != nil {
+			for i, element := range v {
+				s.Items.validate(fmt.Sprintf("%s[%d]", path, i), element, issues)
+			}
+		}
+	}
+}
+
+func typeNames(t interface{}) []string {
+	switch t := t.(type) {
+	case string:
+		return []string{t}
+	case []string:
+		return t
+	}
+	return nil
+}
+
+func hasType(types []string, v interface{}) bool {
+	actual := jsonType(v)
+	for _, t := range types {
+		if t == actual || t == "number" && actual == "integer" {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonType returns the JSON Schema type of a decoded value. Numbers must be
+// decoded as json.Number to tell integers apart.
+func jsonType(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if strings.ContainsAny(v.String(), ".eE") {
+			return "number"
+		}
+		return "integer"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Detect guesses the kind of an output file from the properties of its first
+// record.
+func Detect(data []byte) (Kind, bool) {
+	var one map[string]json.RawMessage
+	if err := json.Unmarshal(data, &one); err != nil {
+		var list []map[string]json.RawMessage
+		if err := json.Unmarshal(data, &list); err != nil || len(list) == 0 {
+			return Kind{}, false
+		}
+		one = list[0]
+	}
+
+	has := func(name string) bool {
+		_, ok := one[name]
+		return ok
+	}
+	switch {
+	case has("Crystal") || has("RequiredItems"):
+		return Lookup("recipe")
+	case has("Items") && has("Zone"):
+		return Lookup("merchants")
+	case has("ItemDropInfos"):
+		return Lookup("harvest")
+	case has("ItemDrops"):
+		return Lookup("drops")
+	case has("ItemName") && has("NPC"):
+		return Lookup("drop-scrape")
+	}
+	return Kind{}, false
+}
+
+// ValidateDir validates every .json file under dir. An empty kind detects
+// the kind of each file; files of no known kind are reported as issues.
+func ValidateDir(dir, kind string) (Report, error) {
+	report := Report{Issues: []Issue{}}
+	schemas := make(map[string]*Schema)
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || strings.ToLower(filepath.Ext(path)) != ".json" {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		report.Files++
+
+		k, ok := Lookup(kind)
+		if kind == "" {
+			k, ok = Detect(data)
+		}
+		if !ok {
+			report.Invalid++
+			report.Issues = append(report.Issues, Issue{File: path, Path: "$", Message: "unknown output format"})
+			return nil
+		}
+		if schemas[k.Name] == nil {
+			schemas[k.Name] = k.Schema()
+		}
+
+		issues, err := schemas[k.Name].Validate(data)
+		if err != nil {
+			issues = []Issue{{Path: "$", Message: err.Error()}}
+		}
+		if len(issues) > 0 {
+			report.Invalid++
+		}
+		for _, issue := range issues {
+			issue.File = path
+			issue.Kind = k.Name
+			report.Issues = append(report.Issues, issue)
+		}
+		return nil
+	})
+	return report, err
+}
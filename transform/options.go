@@ -0,0 +1,108 @@
+package transform
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"sort"
+)
+
+// Output formats of Options.Format.
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+	FormatTSV  = "tsv"
+)
+
+// SchemaVersion is the version of the output formats, stamped on every
+// record the transformers write. Records without one are version 1, written
+// before outputs were versioned.
+const SchemaVersion = 2
+
+// Formats are every output format.
+var Formats = []string{FormatJSON, FormatCSV, FormatTSV}
+
+// Overwrite policies of Options.Overwrite, deciding what happens to output
+// files that already exist.
+const (
+	// OverwriteReplace replaces existing files.
+	OverwriteReplace = "replace"
+	// OverwriteSkip keeps existing files untouched.
+	OverwriteSkip = "skip"
+	// OverwriteMerge adds the new records to the records of existing files,
+	// replacing records with the same key.
+	OverwriteMerge = "merge"
+)
+
+// Options are the settings shared by every transformer.
+type Options struct {
+	// InputDir holds the scraper exports to read.
+	InputDir string
+	// Inputs are the input files, or glob patterns, relative to InputDir.
+	// Empty means the transformer's default files.
+	Inputs []string
+	// Profile renames the columns of the input files.
+	Profile Profile
+	// OutputDir is where transformed files are written.
+	OutputDir string
+	// Format is the output format, one of the Format constants.
+	Format string
+	// Overwrite is one of the Overwrite policies, empty means OverwriteReplace.
+	Overwrite string
+	Verbose   bool
+}
+
+// Logf logs only when Verbose is set.
+func (o Options) Logf(format string, args ...interface{}) {
+	if o.Verbose {
+		log.Printf(format, args...)
+	}
+}
+
+// CheckFormat returns an error for formats a transformer can't write.
+func (o Options) CheckFormat(supported ...string) error {
+	for _, format := range supported {
+		if o.Format == format {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported output format %q, expected one of %v", o.Format, supported)
+}
+
+// InputFiles expands Inputs, or defaults when there are none, into sorted
+// paths under InputDir. A pattern matching nothing is kept as is so reading
+// it reports the missing file.
+func (o Options) InputFiles(defaults ...string) ([]string, error) {
+	patterns := o.Inputs
+	if len(patterns) == 0 {
+		patterns = defaults
+	}
+
+	var files []string
+	for _, pattern := range patterns {
+		path := pattern
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(o.InputDir, path)
+		}
+		matches, err := filepath.Glob(path)
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) == 0 && !hasMeta(pattern) {
+			matches = []string{path}
+		}
+		sort.Strings(matches)
+		files = append(files, matches...)
+	}
+	return files, nil
+}
+
+func hasMeta(pattern string) bool {
+	for _, r := range pattern {
+		switch r {
+		case '*', '?', '[', '\\':
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+package transform
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// WriteJSON writes v as indented JSON to name under OutputDir, following the
+// Overwrite policy when the file exists.
+func (o Options) WriteJSON(name string, v interface{}) error {
+	path := filepath.Join(o.OutputDir, name)
+	err := os.MkdirAll(filepath.Dir(path), os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	jsonData, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	existing, err := os.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+	case err != nil:
+		return err
+	case o.Overwrite == OverwriteSkip:
+		o.Logf("Keeping existing %s", path)
+		return nil
+	case o.Overwrite == OverwriteMerge:
+		jsonData, err = mergeRecords(existing, jsonData)
+		if err != nil {
+			return fmt.Errorf("merging into %s: %v", path, err)
+		}
+	}
+
+	return os.WriteFile(path, jsonData, 0644)
+}
+
+// mergeRecords merges two JSON lists of records. New records replace existing
+// records with the same key and the rest are appended, keeping the order of
+// the existing file. Anything but two lists is replaced by the new data.
+func mergeRecords(existing, updated []byte) ([]byte, error) {
+	var oldRecords, newRecords []json.RawMessage
+	if json.Unmarshal(existing, &oldRecords) != nil || json.Unmarshal(updated, &newRecords) != nil {
+		return updated, nil
+	}
+
+	indexByKey := make(map[string]int)
+	for i, record := range oldRecords {
+		indexByKey[recordKey(record)] = i
+	}
+	for _, record := range newRecords {
+		key := recordKey(record)
+		if i, ok := indexByKey[key]; ok {
+			oldRecords[i] = record
+			continue
+		}
+		indexByKey[key] = len(oldRecords)
+		oldRecords = append(oldRecords, record)
+	}
+
+	return json.MarshalIndent(oldRecords, "", "  ")
+}
+
+// recordKey identifies a record by its Name, a scrape row by its item, NPC
+// and zone, and anything else by its whole content.
+func recordKey(record json.RawMessage) string {
+	var fields struct {
+		Name     string
+		ItemName string
+		NPC      string
+		Zone     string
+		ZoneName string
+	}
+	if json.Unmarshal(record, &fields) != nil {
+		return string(record)
+	}
+	switch {
+	case fields.Name != "":
+		return fields.Name + "|" + fields.ZoneName + "|" + fields.Zone
+	case fields.ItemName != "":
+		return fields.ItemName + "|" + fields.NPC + "|" + fields.Zone
+	default:
+		return string(record)
+	}
+}
@@ -0,0 +1,64 @@
+package transform
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Profile maps the column names a transformer expects to the column names of
+// an export, e.g. {"goodsPrice": "Goods"} for a merchant export that calls
+// that column Goods.
+type Profile map[string]string
+
+// Apply renames the keys of every JSON object in data, at any depth. Data
+// that is not JSON can't be mapped and is an error unless the profile is empty.
+func (p Profile) Apply(data []byte) ([]byte, error) {
+	if len(p) == 0 {
+		return data, nil
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, fmt.Errorf("applying column profile: %v", err)
+	}
+	return json.Marshal(p.rename(value))
+}
+
+func (p Profile) rename(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		renamed := make(map[string]interface{}, len(v))
+		for key, child := range v {
+			renamed[key] = p.rename(child)
+		}
+		for column, exported := range p {
+			if child, ok := renamed[exported]; ok && column != exported {
+				renamed[column] = child
+				delete(renamed, exported)
+			}
+		}
+		return renamed
+	case []interface{}:
+		for i, child := range v {
+			v[i] = p.rename(child)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// ReadInput reads an input file and applies the column profile.
+func (o Options) ReadInput(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err = o.Profile.Apply(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return data, nil
+}
@@ -0,0 +1,40 @@
+package transform
+
+import (
+	"path/filepath"
+	"time"
+)
+
+// Provenance traces a transformed record back to the scraper export row it
+// was built from, so a number that looks wrong can be checked at its source.
+// Transformers fill in the row and its links, Run stamps the file and time.
+type Provenance struct {
+	// File is the name of the scraper export, e.g. all_mobs_nineth.json.
+	File string `json:"File"`
+	// Row is the index of the row in File, counting from 0. Harvest inputs
+	// count within the item list of a zone and point type.
+	Row int `json:"Row"`
+	// SourceURL is the page the row was scraped from.
+	SourceURL string `json:"SourceURL,omitempty"`
+	// NPCURL is the page of the mob the row is about.
+	NPCURL string `json:"NPCURL,omitempty"`
+	// Links are the other itemdb links of the row, e.g. of HQ results.
+	Links []string `json:"Links,omitempty"`
+	// TransformedAt is when the transformer ran.
+	TransformedAt time.Time `json:"TransformedAt"`
+}
+
+// Stamp records the export file the row was read from and the time of the
+// run. It does nothing on a nil Provenance.
+func (p *Provenance) Stamp(file string, at time.Time) {
+	if p == nil {
+		return
+	}
+	p.File = filepath.Base(file)
+	p.TransformedAt = at
+}
+
+// Now is the transform time to stamp, in UTC whole seconds.
+func Now() time.Time {
+	return time.Now().UTC().Truncate(time.Second)
+}
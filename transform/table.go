@@ -0,0 +1,118 @@
+package transform
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// Table is a flattened dataset with stable column names, written as CSV or TSV.
+type Table struct {
+	Columns []string
+	Rows    [][]string
+}
+
+// Write writes the header and rows separated by comma, quoting fields as needed.
+func (t Table) Write(w io.Writer, comma rune) error {
+	writer := csv.NewWriter(w)
+	writer.Comma = comma
+	if err := writer.Write(t.Columns); err != nil {
+		return err
+	}
+	if err := writer.WriteAll(t.Rows); err != nil {
+		return err
+	}
+	return writer.Error()
+}
+
+// Write writes records under OutputDir as name plus the extension of Format,
+// as JSON or as the flattened table. It follows the Overwrite policy; merged
+// tables keep the existing rows and append the new rows not already there.
+func (o Options) Write(name string, v interface{}, table func() Table) error {
+	if o.Format == FormatJSON || o.Format == "" {
+		return o.WriteJSON(name+".json", v)
+	}
+
+	comma, ok := separators[o.Format]
+	if !ok {
+		return fmt.Errorf("unsupported output format %q", o.Format)
+	}
+
+	path := filepath.Join(o.OutputDir, name+"."+o.Format)
+	err := os.MkdirAll(filepath.Dir(path), os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	t := table()
+	existing, err := os.Open(path)
+	switch {
+	case os.IsNotExist(err):
+	case err != nil:
+		return err
+	case o.Overwrite == OverwriteSkip:
+		existing.Close()
+		o.Logf("Keeping existing %s", path)
+		return nil
+	case o.Overwrite == OverwriteMerge:
+		t, err = mergeTable(existing, comma, t)
+		existing.Close()
+		if err != nil {
+			return fmt.Errorf("merging into %s: %v", path, err)
+		}
+	default:
+		existing.Close()
+	}
+
+	var buf bytes.Buffer
+	if err := t.Write(&buf, comma); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+// Separators of the table formats.
+var separators = map[string]rune{
+	FormatCSV: ',',
+	FormatTSV: '\t',
+}
+
+// mergeTable appends the rows of t that r doesn't have yet. A file with other
+// columns is replaced.
+func mergeTable(r io.Reader, comma rune, t Table) (Table, error) {
+	reader := csv.NewReader(r)
+	reader.Comma = comma
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return Table{}, err
+	}
+	if len(records) == 0 || fmt.Sprint(records[0]) != fmt.Sprint(t.Columns) {
+		return t, nil
+	}
+
+	merged := Table{Columns: t.Columns, Rows: records[1:]}
+	seen := make(map[string]bool)
+	for _, row := range merged.Rows {
+		seen[fmt.Sprintf("%q", row)] = true
+	}
+	for _, row := range t.Rows {
+		if !seen[fmt.Sprintf("%q", row)] {
+			seen[fmt.Sprintf("%q", row)] = true
+			merged.Rows = append(merged.Rows, row)
+		}
+	}
+	return merged, nil
+}
+
+// FormatFloat formats an optional number for a table, empty when nil.
+func FormatFloat(f *float64) string {
+	if f == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*f, 'f', -1, 64)
+}
@@ -0,0 +1,114 @@
+package transform
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestProfileApply(t *testing.T) {
+	profile := Profile{"goodsPrice": "Goods", "merchant": "Name"}
+
+	mapped, err := profile.Apply([]byte(`[{"Name": "Dahjal", "Goods": "Ash Log 90 gil", "location": "Port Bastok (H-7)"}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var rows []map[string]string
+	if err := json.Unmarshal(mapped, &rows); err != nil {
+		t.Fatal(err)
+	}
+	expected := []map[string]string{{"merchant": "Dahjal", "goodsPrice": "Ash Log 90 gil", "location": "Port Bastok (H-7)"}}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Errorf("Expected %v, but got %v", expected, rows)
+	}
+}
+
+func TestWriteJSONOverwrite(t *testing.T) {
+	existing := []map[string]string{{"Name": "Bogy", "ZoneName": "Valkurm_Dunes"}, {"Name": "Goblin Shepherd", "ZoneName": "Valkurm_Dunes"}}
+	updated := []map[string]string{{"Name": "Bogy", "ZoneName": "Valkurm_Dunes", "Note": "new"}, {"Name": "Snipper", "ZoneName": "Valkurm_Dunes"}}
+
+	testCases := []struct {
+		overwrite string
+		expected  []map[string]string
+	}{
+		{OverwriteReplace, updated},
+		{OverwriteSkip, existing},
+		{OverwriteMerge, []map[string]string{updated[0], existing[1], updated[1]}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.overwrite, func(t *testing.T) {
+			opts := Options{OutputDir: t.TempDir(), Overwrite: tc.overwrite}
+			if err := opts.WriteJSON("mobs.json", existing); err != nil {
+				t.Fatal(err)
+			}
+			if err := opts.WriteJSON("mobs.json", updated); err != nil {
+				t.Fatal(err)
+			}
+
+			fileContent, err := os.ReadFile(filepath.Join(opts.OutputDir, "mobs.json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var records []map[string]string
+			if err := json.Unmarshal(fileContent, &records); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(records, tc.expected) {
+				t.Errorf("Expected %v, but got %v", tc.expected, records)
+			}
+		})
+	}
+}
+
+func TestTableWrite(t *testing.T) {
+	table := Table{
+		Columns: []string{"Merchant", "Item", "MinPrice"},
+		Rows:    [][]string{{"Ostalie", "San d'Orian Grape", "60"}, {"Dahjal", "Cheese Sandwich, Large", "120"}},
+	}
+
+	testCases := []struct {
+		comma    rune
+		expected string
+	}{
+		{',', "Merchant,Item,MinPrice\nOstalie,San d'Orian Grape,60\nDahjal,\"Cheese Sandwich, Large\",120\n"},
+		{'\t', "Merchant\tItem\tMinPrice\nOstalie\tSan d'Orian Grape\t60\nDahjal\tCheese Sandwich, Large\t120\n"},
+	}
+
+	for _, tc := range testCases {
+		var buf bytes.Buffer
+		if err := table.Write(&buf, tc.comma); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != tc.expected {
+			t.Errorf("Expected %q, but got %q", tc.expected, buf.String())
+		}
+	}
+}
+
+func TestWriteTableMerge(t *testing.T) {
+	opts := Options{OutputDir: t.TempDir(), Format: FormatCSV, Overwrite: OverwriteMerge}
+	columns := []string{"Item", "Price"}
+	first := Table{Columns: columns, Rows: [][]string{{"Ash Log", "90"}, {"Wind Crystal", "20"}}}
+	second := Table{Columns: columns, Rows: [][]string{{"Wind Crystal", "20"}, {"Flint Stone", "30"}}}
+
+	for _, table := range []Table{first, second} {
+		table := table
+		if err := opts.Write("goods", nil, func() Table { return table }); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fileContent, err := os.ReadFile(filepath.Join(opts.OutputDir, "goods.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "Item,Price\nAsh Log,90\nWind Crystal,20\nFlint Stone,30\n"
+	if string(fileContent) != expected {
+		t.Errorf("Expected %q, but got %q", expected, fileContent)
+	}
+}
@@ -0,0 +1,127 @@
+// Package zone is the registry of canonical zones that joins every dataset,
+// resolving the spellings the scrapes use.
+package zone
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Zone is a canonical zone shared by every dataset.
+type Zone struct {
+	// ID is the canonical file and join key, e.g. Valkurm_Dunes.
+	ID string `json:"ID"`
+	// Name is the display name, e.g. Valkurm Dunes.
+	Name string `json:"Name"`
+	// Aliases are other spellings used by scrapes, e.g. Bastok Port.
+	Aliases []string `json:"Aliases,omitempty"`
+	// Region is the region of the zone, or the nation for city zones.
+	Region string `json:"Region"`
+}
+
+var (
+	byKey          = make(map[string]Zone)
+	ambiguousByKey = make(map[string][]string)
+)
+
+func init() {
+	for _, z := range zones {
+		byKey[key(z.ID)] = z
+		byKey[key(z.Name)] = z
+		for _, alias := range z.Aliases {
+			byKey[key(alias)] = z
+		}
+	}
+	for name, ids := range ambiguous {
+		ambiguousByKey[key(name)] = ids
+	}
+}
+
+// Lookup resolves any known spelling of a zone. Ambiguous spellings are not
+// resolved, see Candidates.
+func Lookup(name string) (Zone, bool) {
+	z, ok := byKey[key(name)]
+	return z, ok
+}
+
+// Candidates returns the zones an ambiguous spelling may refer to, e.g. both
+// ships for "Ferry Between Mhaura & Selbina". It returns false for any other
+// spelling.
+func Candidates(name string) ([]Zone, bool) {
+	ids, ok := ambiguousByKey[key(name)]
+	if !ok {
+		return nil, false
+	}
+	candidates := make([]Zone, 0, len(ids))
+	for _, id := range ids {
+		candidates = append(candidates, byKey[key(id)])
+	}
+	return candidates, true
+}
+
+// ID returns the canonical ID of a zone. Unknown and ambiguous zones fall
+// back to Slug so they still get a stable spelling.
+func ID(name string) string {
+	if z, ok := Lookup(name); ok {
+		return z.ID
+	}
+	return Slug(name)
+}
+
+// Same reports whether two spellings refer to the same zone.
+func Same(a, b string) bool {
+	return ID(a) == ID(b)
+}
+
+// All returns every known zone sorted by ID.
+func All() []Zone {
+	all := make([]Zone, len(zones))
+	copy(all, zones)
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].ID < all[j].ID
+	})
+	return all
+}
+
+// Slug turns an unknown zone name into an ID by moving a leading direction or
+// "Port" to the front, joining words with underscores and dropping anything
+// that isn't a letter, digit or underscore.
+func Slug(name string) string {
+	// Split the zone name into words
+	words := strings.Fields(strings.ReplaceAll(name, "_", " "))
+
+	// Check for cardinal directions or the word "port" and move them to the front
+	for i, word := range words {
+		lowercaseWord := strings.ToLower(word)
+		if lowercaseWord == "north" || lowercaseWord == "south" || lowercaseWord == "east" || lowercaseWord == "west" || lowercaseWord == "port" {
+			// Move the word to the front
+			words = append([]string{word}, append(words[:i], words[i+1:]...)...)
+			break
+		}
+	}
+
+	// Join the words back together
+	sanitized := strings.Join(words, "_")
+	// Remove special characters and apostrophes
+	sanitized = nonIDCharacters.ReplaceAllString(sanitized, "")
+
+	return sanitized
+}
+
+var (
+	nonIDCharacters  = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+	shadowreignRe    = regexp.MustCompile(`\[s\]|\(s\)`)
+	nonKeyCharacters = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// key normalizes a spelling for lookups. Case, apostrophes, punctuation and
+// word order are ignored, so "Bastok Port" and "Port_Bastok" share a key.
+func key(name string) string {
+	name = strings.ToLower(name)
+	name = shadowreignRe.ReplaceAllString(name, " s ")
+	name = strings.ReplaceAll(name, "'", "")
+	words := strings.Fields(nonKeyCharacters.ReplaceAllString(name, " "))
+	sort.Strings(words)
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,86 @@
+package zone
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestID(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected string
+	}{
+		{"Valkurm Dunes", "Valkurm_Dunes"},
+		{"Valkurm_Dunes", "Valkurm_Dunes"},
+		{"valkurm dunes", "Valkurm_Dunes"},
+		{"Bastok Port", "Port_Bastok"},
+		{"Jeuno Lower", "Lower_Jeuno"},
+		{"San d'Oria North", "Northern_San_dOria"},
+		{"Ru'Lude Gardens", "RuLude_Gardens"},
+		{"RuLude_Gardens", "RuLude_Gardens"},
+		{"West Sarutabaruta [S]", "West_Sarutabaruta_S"},
+		{"West_Sarutabaruta", "West_Sarutabaruta"},
+		{"Maze Of Shakhrami", "Maze_of_Shakhrami"},
+		{"Abyssea_-_Grauberg", "Abyssea_-_Grauberg"},
+		{"Ship bound for Mhaura", "Ship_bound_for_Mhaura"},
+		{"Ship bound for Selbina", "Ship_bound_for_Selbina"},
+		// Ambiguous zones fall back to Slug as well
+		{"Ferry Between Mhaura & Selbina", "Ferry_Between_Mhaura__Selbina"},
+		// Unknown zones fall back to Slug
+		{"Sky Ru'Aun North", "North_Sky_RuAun"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := ID(tc.name)
+			if result != tc.expected {
+				t.Errorf("Expected %s, but got %s", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestAllKeysAreUnique(t *testing.T) {
+	owners := make(map[string]string)
+	for _, z := range All() {
+		for _, name := range append([]string{z.ID, z.Name}, z.Aliases...) {
+			if owner, ok := owners[key(name)]; ok && owner != z.ID {
+				t.Errorf("Spelling %q of %s is also a spelling of %s", name, z.ID, owner)
+			}
+			owners[key(name)] = z.ID
+		}
+	}
+}
+
+func TestCandidates(t *testing.T) {
+	candidates, ok := Candidates("Ferry Between Mhaura & Selbina")
+	if !ok {
+		t.Fatal("Expected the ferry to be ambiguous")
+	}
+	var ids []string
+	for _, z := range candidates {
+		ids = append(ids, z.ID)
+	}
+	if expected := []string{"Ship_bound_for_Selbina", "Ship_bound_for_Mhaura"}; !reflect.DeepEqual(ids, expected) {
+		t.Errorf("Expected candidates %v, but got %v", expected, ids)
+	}
+	if _, ok := Lookup("Ferry Between Mhaura & Selbina"); ok {
+		t.Error("Expected Lookup not to resolve an ambiguous spelling")
+	}
+	if _, ok := Candidates("Ship bound for Mhaura"); ok {
+		t.Error("Expected a single zone not to be ambiguous")
+	}
+}
+
+func TestAmbiguousSpellings(t *testing.T) {
+	for name, ids := range ambiguous {
+		if _, ok := byKey[key(name)]; ok {
+			t.Errorf("Ambiguous spelling %q is also a zone spelling", name)
+		}
+		for _, id := range ids {
+			if _, ok := byKey[key(id)]; !ok {
+				t.Errorf("Ambiguous spelling %q refers to unknown zone %s", name, id)
+			}
+		}
+	}
+}
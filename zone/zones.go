@@ -0,0 +1,123 @@
+package zone
+
+// zones is the registry of every zone used by the datasets. Add aliases here
+// when a scrape spells a zone a new way.
+var zones = []Zone{
+	// San d'Oria
+	{ID: "Northern_San_dOria", Name: "Northern San d'Oria", Aliases: []string{"San d'Oria North", "North San d'Oria"}, Region: "San d'Oria"},
+	{ID: "Southern_San_dOria", Name: "Southern San d'Oria", Aliases: []string{"San d'Oria South", "South San d'Oria"}, Region: "San d'Oria"},
+	{ID: "Port_San_dOria", Name: "Port San d'Oria", Region: "San d'Oria"},
+	{ID: "Chateau_dOraguille", Name: "Chateau d'Oraguille", Region: "San d'Oria"},
+
+	// Bastok
+	{ID: "Bastok_Markets", Name: "Bastok Markets", Region: "Bastok"},
+	{ID: "Bastok_Mines", Name: "Bastok Mines", Region: "Bastok"},
+	{ID: "Port_Bastok", Name: "Port Bastok", Region: "Bastok"},
+	{ID: "Metalworks", Name: "Metalworks", Region: "Bastok"},
+
+	// Windurst
+	{ID: "Windurst_Waters", Name: "Windurst Waters", Region: "Windurst"},
+	{ID: "Windurst_Woods", Name: "Windurst Woods", Region: "Windurst"},
+	{ID: "Windurst_Walls", Name: "Windurst Walls", Region: "Windurst"},
+	{ID: "Port_Windurst", Name: "Port Windurst", Region: "Windurst"},
+	{ID: "Heavens_Tower", Name: "Heavens Tower", Region: "Windurst"},
+
+	// Jeuno
+	{ID: "RuLude_Gardens", Name: "Ru'Lude Gardens", Region: "Jeuno"},
+	{ID: "Upper_Jeuno", Name: "Upper Jeuno", Aliases: []string{"Jeuno Upper"}, Region: "Jeuno"},
+	{ID: "Lower_Jeuno", Name: "Lower Jeuno", Aliases: []string{"Jeuno Lower"}, Region: "Jeuno"},
+	{ID: "Port_Jeuno", Name: "Port Jeuno", Region: "Jeuno"},
+
+	// Ronfaure
+	{ID: "West_Ronfaure", Name: "West Ronfaure", Region: "Ronfaure"},
+	{ID: "East_Ronfaure", Name: "East Ronfaure", Region: "Ronfaure"},
+	{ID: "King_Ranperres_Tomb", Name: "King Ranperre's Tomb", Region: "Ronfaure"},
+	{ID: "Ghelsba_Outpost", Name: "Ghelsba Outpost", Region: "Ronfaure"},
+	{ID: "Fort_Ghelsba", Name: "Fort Ghelsba", Region: "Ronfaure"},
+	{ID: "Yughott_Grotto", Name: "Yughott Grotto", Region: "Ronfaure"},
+
+	// Zulkheim
+	{ID: "Valkurm_Dunes", Name: "Valkurm Dunes", Region: "Zulkheim"},
+	{ID: "La_Theine_Plateau", Name: "La Theine Plateau", Region: "Zulkheim"},
+	{ID: "Konschtat_Highlands", Name: "Konschtat Highlands", Region: "Zulkheim"},
+	{ID: "Ordelles_Caves", Name: "Ordelle's Caves", Region: "Zulkheim"},
+	{ID: "Gusgen_Mines", Name: "Gusgen Mines", Region: "Zulkheim"},
+	{ID: "Selbina", Name: "Selbina", Region: "Zulkheim"},
+
+	// Norvallen
+	{ID: "Jugner_Forest", Name: "Jugner Forest", Region: "Norvallen"},
+	{ID: "Batallia_Downs", Name: "Batallia Downs", Region: "Norvallen"},
+	{ID: "Davoi", Name: "Davoi", Region: "Norvallen"},
+	{ID: "Carpenters_Landing", Name: "Carpenters' Landing", Region: "Norvallen"},
+
+	// Gustaberg
+	{ID: "North_Gustaberg", Name: "North Gustaberg", Region: "Gustaberg"},
+	{ID: "South_Gustaberg", Name: "South Gustaberg", Region: "Gustaberg"},
+	{ID: "Dangruf_Wadi", Name: "Dangruf Wadi", Region: "Gustaberg"},
+	{ID: "Palborough_Mines", Name: "Palborough Mines", Region: "Gustaberg"},
+	{ID: "Zeruhn_Mines", Name: "Zeruhn Mines", Region: "Gustaberg"},
+	{ID: "Grauberg_S", Name: "Grauberg [S]", Region: "Gustaberg"},
+
+	// Derfland
+	{ID: "Pashhow_Marshlands", Name: "Pashhow Marshlands", Region: "Derfland"},
+	{ID: "Rolanberry_Fields", Name: "Rolanberry Fields", Region: "Derfland"},
+	{ID: "Beadeaux", Name: "Beadeaux", Region: "Derfland"},
+
+	// Sarutabaruta
+	{ID: "West_Sarutabaruta", Name: "West Sarutabaruta", Region: "Sarutabaruta"},
+	{ID: "East_Sarutabaruta", Name: "East Sarutabaruta", Region: "Sarutabaruta"},
+	{ID: "West_Sarutabaruta_S", Name: "West Sarutabaruta [S]", Region: "Sarutabaruta"},
+	{ID: "Inner_Horutoto_Ruins", Name: "Inner Horutoto Ruins", Region: "Sarutabaruta"},
+	{ID: "Outer_Horutoto_Ruins", Name: "Outer Horutoto Ruins", Region: "Sarutabaruta"},
+	{ID: "Giddeus", Name: "Giddeus", Region: "Sarutabaruta"},
+	{ID: "Fort_Karugo-Narugo_S", Name: "Fort Karugo-Narugo [S]", Region: "Sarutabaruta"},
+
+	// Kolshushu
+	{ID: "Tahrongi_Canyon", Name: "Tahrongi Canyon", Region: "Kolshushu"},
+	{ID: "Buburimu_Peninsula", Name: "Buburimu Peninsula", Region: "Kolshushu"},
+	{ID: "Maze_of_Shakhrami", Name: "Maze of Shakhrami", Region: "Kolshushu"},
+	{ID: "Mhaura", Name: "Mhaura", Region: "Kolshushu"},
+	{ID: "Bibiki_Bay", Name: "Bibiki Bay", Region: "Kolshushu"},
+	{ID: "Bibiki_Bay_-_Purgonorgo_Isle", Name: "Bibiki Bay - Purgonorgo Isle", Aliases: []string{"Purgonorgo Isle"}, Region: "Kolshushu"},
+
+	// Aragoneu
+	{ID: "Meriphataud_Mountains", Name: "Meriphataud Mountains", Region: "Aragoneu"},
+	{ID: "Sauromugue_Champaign", Name: "Sauromugue Champaign", Region: "Aragoneu"},
+
+	// Qufim
+	{ID: "Qufim_Island", Name: "Qufim Island", Region: "Qufim"},
+	{ID: "Lower_Delkfutts_Tower", Name: "Lower Delkfutt's Tower", Region: "Qufim"},
+
+	// Kuzotz
+	{ID: "Rabao", Name: "Rabao", Region: "Kuzotz"},
+
+	// Elshimo
+	{ID: "Yuhtunga_Jungle", Name: "Yuhtunga Jungle", Region: "Elshimo Lowlands"},
+	{ID: "Norg", Name: "Norg", Region: "Elshimo Lowlands"},
+	{ID: "Yhoator_Jungle", Name: "Yhoator Jungle", Region: "Elshimo Uplands"},
+	{ID: "Kazham", Name: "Kazham", Region: "Elshimo Uplands"},
+
+	// Movalpolos
+	{ID: "Oldton_Movalpolos", Name: "Oldton Movalpolos", Region: "Movalpolos"},
+
+	// Tavnazian Archipelago
+	{ID: "Tavnazian_Safehold", Name: "Tavnazian Safehold", Region: "Tavnazian Archipelago"},
+
+	// Aht Urhgan
+	{ID: "Bhaflau_Thickets", Name: "Bhaflau Thickets", Region: "Aht Urhgan"},
+	{ID: "Wajaom_Woodlands", Name: "Wajaom Woodlands", Region: "Aht Urhgan"},
+
+	// Abyssea
+	{ID: "Abyssea_-_Grauberg", Name: "Abyssea - Grauberg", Region: "Abyssea"},
+
+	// Ferries
+	{ID: "Ship_bound_for_Selbina", Name: "Ship bound for Selbina", Region: "Ferries"},
+	{ID: "Ship_bound_for_Mhaura", Name: "Ship bound for Mhaura", Region: "Ferries"},
+}
+
+// ambiguous are scrape spellings that name more than one zone. They are not
+// aliases of any of them, Lookup doesn't resolve them.
+var ambiguous = map[string][]string{
+	// The ferry runs both ways, the scrape doesn't say which ship
+	"Ferry Between Mhaura & Selbina": {"Ship_bound_for_Selbina", "Ship_bound_for_Mhaura"},
+}